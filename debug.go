@@ -0,0 +1,125 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/opencontainers/runc/libcontainer/seccomp"
+	"github.com/opencontainers/runc/libcontainer/specconv"
+	"github.com/opencontainers/runtime-spec/specs-go"
+	"github.com/urfave/cli"
+)
+
+var debugCommand = cli.Command{
+	Name:  "debug",
+	Usage: "debug container configurations",
+	Subcommands: []cli.Command{
+		debugSeccompCommand,
+	},
+}
+
+var debugSeccompCommand = cli.Command{
+	Name:      "seccomp",
+	Usage:     "display and evaluate the seccomp program of a container",
+	ArgsUsage: "",
+	Description: `The seccomp command compiles the seccomp profile of a container config into
+the BPF program runc loads, including the stub returning ENOSYS for unknown
+system calls, without running the container.
+
+With --dump, the program is displayed with annotations. With --eval, it is
+run for the given system call, and the resulting action is displayed. The
+system call is written as "name(arch=ARCH, ARG...)", where name is a system
+call name or number, ARCH is the architecture (native by default), and the
+arguments are numbers. --eval can be repeated. Without --eval, --dump is
+implied.
+
+For example:
+
+       # runc debug seccomp --eval 'openat(arch=x86_64, -100, 0)'`,
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "config",
+			Value: specConfig,
+			Usage: "path to the container config, or to a seccomp profile as written by run --seccomp-record",
+		},
+		cli.BoolFlag{
+			Name:  "dump",
+			Usage: "display the annotated seccomp program",
+		},
+		cli.StringSliceFlag{
+			Name:  "eval",
+			Usage: "evaluate the seccomp program for a system call",
+		},
+	},
+	Action: func(context *cli.Context) error {
+		if err := checkArgs(context, 0, exactArgs); err != nil {
+			return err
+		}
+		var calls []*seccomp.Call
+		for _, s := range context.StringSlice("eval") {
+			call, err := seccomp.ParseCall(s)
+			if err != nil {
+				return err
+			}
+			calls = append(calls, call)
+		}
+		profile, err := loadSeccompProfile(context.String("config"))
+		if err != nil {
+			return err
+		}
+		config, err := specconv.SetupSeccomp(profile)
+		if err != nil {
+			return err
+		}
+		if config == nil {
+			return errors.New("seccomp is disabled by the profile")
+		}
+		program, err := seccomp.Compile(config)
+		if err != nil {
+			return err
+		}
+
+		if context.Bool("dump") || len(calls) == 0 {
+			if err := program.Dump(os.Stdout); err != nil {
+				return err
+			}
+		}
+		for _, call := range calls {
+			action, err := program.Eval(call)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("%s = %s\n", call, action)
+		}
+		return nil
+	},
+}
+
+// loadSeccompProfile loads the linux.seccomp section of the container config
+// at path, or the seccomp profile at path if it is not a container config.
+func loadSeccompProfile(path string) (*specs.LinuxSeccomp, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var spec specs.Spec
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %w", path, err)
+	}
+	if spec.Linux != nil {
+		if spec.Linux.Seccomp == nil {
+			return nil, fmt.Errorf("%s has no seccomp profile", path)
+		}
+		return spec.Linux.Seccomp, nil
+	}
+	var profile specs.LinuxSeccomp
+	if err := json.Unmarshal(data, &profile); err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %w", path, err)
+	}
+	if profile.DefaultAction == "" {
+		return nil, fmt.Errorf("%s is neither a container config nor a seccomp profile", path)
+	}
+	return &profile, nil
+}
@@ -5,6 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -28,6 +30,10 @@ information is displayed once every 5 seconds.`,
 	Flags: []cli.Flag{
 		cli.DurationFlag{Name: "interval", Value: 5 * time.Second, Usage: "set the stats collection interval"},
 		cli.BoolFlag{Name: "stats", Usage: "display the container's stats then exit"},
+		cli.StringFlag{Name: "memory-pressure", Usage: `report memory pressure events, either for a level ("low", "medium" or "critical") or for a cgroup v2 PSI trigger (e.g. "some 150000 1000000")`},
+		cli.StringFlag{Name: "format", Value: "json", Usage: `select the output format ("json" or "openmetrics")`},
+		cli.StringFlag{Name: "listen", Usage: `serve the container's stats in the OpenMetrics format over HTTP on /metrics, at "unix:<path>" or "tcp:<address>"`},
+		cli.StringFlag{Name: "stats-only", Usage: "only collect the given comma-separated stats (" + strings.Join(statsNames(), ", ") + ")"},
 	},
 	Action: func(context *cli.Context) error {
 		if err := checkArgs(context, 1, exactArgs); err != nil {
@@ -41,6 +47,20 @@ information is displayed once every 5 seconds.`,
 		if duration <= 0 {
 			return errors.New("duration interval must be greater than 0")
 		}
+		format := context.String("format")
+		if format != "json" && format != "openmetrics" {
+			return fmt.Errorf("invalid format %q", format)
+		}
+		mask := libcontainer.StatsAll
+		if only := context.String("stats-only"); only != "" {
+			if mask, err = parseStatsMask(only); err != nil {
+				return err
+			}
+		}
+		listen := context.String("listen")
+		if listen != "" && context.IsSet("format") && format != "openmetrics" {
+			return errors.New("--listen only supports the openmetrics format")
+		}
 		status, err := container.Status()
 		if err != nil {
 			return err
@@ -48,45 +68,70 @@ information is displayed once every 5 seconds.`,
 		if status == libcontainer.Stopped {
 			return fmt.Errorf("container with id %s is not running", container.ID())
 		}
+		if listen != "" {
+			return serveMetrics(container, mask, listen, duration)
+		}
 		var (
-			stats  = make(chan *libcontainer.Stats, 1)
-			events = make(chan *types.Event, 1024)
-			group  = &sync.WaitGroup{}
+			stats   = make(chan *types.Stats, 1)
+			events  = make(chan *types.Event, 1024)
+			written = make(chan struct{}, 1)
+			group   = &sync.WaitGroup{}
 		)
 		group.Add(1)
 		go func() {
 			defer group.Done()
 			enc := json.NewEncoder(os.Stdout)
 			for e := range events {
-				if err := enc.Encode(e); err != nil {
+				if format == "openmetrics" {
+					// Only the stats have a metrics representation.
+					if s, ok := e.Data.(*types.Stats); ok && s != nil {
+						m := newMetricSet()
+						m.addStats(e.ID, s)
+						if _, err := m.WriteTo(os.Stdout); err != nil {
+							logrus.Error(err)
+						}
+					}
+				} else if err := enc.Encode(e); err != nil {
 					logrus.Error(err)
 				}
+				if e.Type == "stats" {
+					written <- struct{}{}
+				}
 			}
 		}()
 		if context.Bool("stats") {
-			s, err := container.Stats()
-			if err != nil {
+			var s libcontainer.Stats
+			if err := container.FillStats(&s, mask); err != nil {
 				return err
 			}
-			events <- &types.Event{Type: "stats", ID: container.ID(), Data: convertLibcontainerStats(s)}
+			events <- &types.Event{Type: "stats", ID: container.ID(), Data: convertLibcontainerStats(&s)}
 			close(events)
 			group.Wait()
 			return nil
 		}
 		go func() {
+			// s is reused, so the converted stats, which share its maps
+			// and slices, must be written before it is filled again.
+			var s libcontainer.Stats
 			for range time.Tick(context.Duration("interval")) {
-				s, err := container.Stats()
-				if err != nil {
+				if err := container.FillStats(&s, mask); err != nil {
 					logrus.Error(err)
 					continue
 				}
-				stats <- s
+				stats <- convertLibcontainerStats(&s)
+				<-written
 			}
 		}()
 		n, err := container.NotifyOOM()
 		if err != nil {
 			return err
 		}
+		var p <-chan struct{}
+		if pressure := context.String("memory-pressure"); pressure != "" {
+			if p, err = notifyMemoryPressure(container, pressure); err != nil {
+				return err
+			}
+		}
 		for {
 			select {
 			case _, ok := <-n:
@@ -98,8 +143,17 @@ information is displayed once every 5 seconds.`,
 				} else {
 					n = nil
 				}
+			case _, ok := <-p:
+				if ok {
+					events <- &types.Event{Type: "pressure", ID: container.ID(), Data: &types.Pressure{
+						Resource: "memory",
+						Trigger:  context.String("memory-pressure"),
+					}}
+				} else {
+					p = nil
+				}
 			case s := <-stats:
-				events <- &types.Event{Type: "stats", ID: container.ID(), Data: convertLibcontainerStats(s)}
+				events <- &types.Event{Type: "stats", ID: container.ID(), Data: s}
 			}
 			if n == nil {
 				close(events)
@@ -111,6 +165,73 @@ information is displayed once every 5 seconds.`,
 	},
 }
 
+// notifyMemoryPressure subscribes to the memory pressure notifications
+// described by pressure, which is either a pressure level name or a raw
+// cgroup v2 PSI trigger.
+func notifyMemoryPressure(container libcontainer.Container, pressure string) (<-chan struct{}, error) {
+	switch pressure {
+	case "low":
+		return container.NotifyMemoryPressure(libcontainer.LowPressure)
+	case "medium":
+		return container.NotifyMemoryPressure(libcontainer.MediumPressure)
+	case "critical":
+		return container.NotifyMemoryPressure(libcontainer.CriticalPressure)
+	}
+
+	var (
+		kind              string
+		threshold, window int64
+	)
+	if _, err := fmt.Sscanf(pressure, "%s %d %d", &kind, &threshold, &window); err != nil || (kind != "some" && kind != "full") {
+		return nil, fmt.Errorf("invalid memory pressure %q: must be a level or a \"some|full <threshold us> <window us>\" trigger", pressure)
+	}
+	return container.NotifyMemoryPressureTrigger(libcontainer.PSITrigger{
+		Full:      kind == "full",
+		Threshold: time.Duration(threshold) * time.Microsecond,
+		Window:    time.Duration(window) * time.Microsecond,
+	})
+}
+
+// statsMasks are the names of the stats which can be selected with
+// --stats-only.
+var statsMasks = map[string]libcontainer.StatsMask{
+	"cpu":       libcontainer.StatsCPU,
+	"cpuset":    libcontainer.StatsCPUSet,
+	"memory":    libcontainer.StatsMemory,
+	"pids":      libcontainer.StatsPids,
+	"blkio":     libcontainer.StatsBlkio,
+	"io":        libcontainer.StatsBlkio,
+	"hugetlb":   libcontainer.StatsHugetlb,
+	"rdma":      libcontainer.StatsRdma,
+	"intel_rdt": libcontainer.StatsIntelRdt,
+	"network":   libcontainer.StatsNetwork,
+}
+
+func statsNames() []string {
+	names := make([]string, 0, len(statsMasks))
+	for name := range statsMasks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// parseStatsMask parses a comma-separated list of stats names.
+func parseStatsMask(s string) (libcontainer.StatsMask, error) {
+	var mask libcontainer.StatsMask
+	for _, name := range strings.Split(s, ",") {
+		m, ok := statsMasks[strings.TrimSpace(name)]
+		if !ok {
+			return 0, fmt.Errorf("invalid stats %q: must be one of %s", name, strings.Join(statsNames(), ", "))
+		}
+		mask |= m
+	}
+	return mask, nil
+}
+
+// convertLibcontainerStats converts ls into the events stats. The result
+// shares some maps and slices with ls, so it must not be used once ls is
+// filled again.
 func convertLibcontainerStats(ls *libcontainer.Stats) *types.Stats {
 	cg := ls.CgroupStats
 	if cg == nil {
@@ -119,6 +240,8 @@ func convertLibcontainerStats(ls *libcontainer.Stats) *types.Stats {
 	var s types.Stats
 	s.Pids.Current = cg.PidsStats.Current
 	s.Pids.Limit = cg.PidsStats.Limit
+	s.Pids.Peak = cg.PidsStats.Peak
+	s.Pids.MaxEvents = cg.PidsStats.MaxEvents
 
 	s.CPU.Usage.Kernel = cg.CpuStats.CpuUsage.UsageInKernelmode
 	s.CPU.Usage.User = cg.CpuStats.CpuUsage.UsageInUsermode
@@ -129,6 +252,7 @@ func convertLibcontainerStats(ls *libcontainer.Stats) *types.Stats {
 	s.CPU.Throttling.Periods = cg.CpuStats.ThrottlingData.Periods
 	s.CPU.Throttling.ThrottledPeriods = cg.CpuStats.ThrottlingData.ThrottledPeriods
 	s.CPU.Throttling.ThrottledTime = cg.CpuStats.ThrottlingData.ThrottledTime
+	s.CPU.PSI = convertPSI(cg.CpuStats.PSI)
 
 	s.CPUSet = types.CPUSet(cg.CPUSetStats)
 
@@ -138,6 +262,8 @@ func convertLibcontainerStats(ls *libcontainer.Stats) *types.Stats {
 	s.Memory.Swap = convertMemoryEntry(cg.MemoryStats.SwapUsage)
 	s.Memory.Usage = convertMemoryEntry(cg.MemoryStats.Usage)
 	s.Memory.Raw = cg.MemoryStats.Stats
+	s.Memory.Events = types.MemoryEvents(cg.MemoryStats.Events)
+	s.Memory.PSI = convertPSI(cg.MemoryStats.PSI)
 
 	s.Blkio.IoServiceBytesRecursive = convertBlkioEntry(cg.BlkioStats.IoServiceBytesRecursive)
 	s.Blkio.IoServicedRecursive = convertBlkioEntry(cg.BlkioStats.IoServicedRecursive)
@@ -147,6 +273,7 @@ func convertLibcontainerStats(ls *libcontainer.Stats) *types.Stats {
 	s.Blkio.IoMergedRecursive = convertBlkioEntry(cg.BlkioStats.IoMergedRecursive)
 	s.Blkio.IoTimeRecursive = convertBlkioEntry(cg.BlkioStats.IoTimeRecursive)
 	s.Blkio.SectorsRecursive = convertBlkioEntry(cg.BlkioStats.SectorsRecursive)
+	s.Blkio.PSI = convertPSI(cg.BlkioStats.PSI)
 
 	s.Hugetlb = make(map[string]types.Hugetlb)
 	for k, v := range cg.HugetlbStats {
@@ -193,6 +320,16 @@ func convertMemoryEntry(c cgroups.MemoryData) types.MemoryEntry {
 	}
 }
 
+func convertPSI(p *cgroups.PSIStats) *types.PSIStats {
+	if p == nil {
+		return nil
+	}
+	return &types.PSIStats{
+		Some: types.PSIData(p.Some),
+		Full: types.PSIData(p.Full),
+	}
+}
+
 func convertBlkioEntry(c []cgroups.BlkioStatEntry) []types.BlkioEntry {
 	var out []types.BlkioEntry
 	for _, e := range c {
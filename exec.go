@@ -95,6 +95,26 @@ following will output a list of processes running in the container:
 			Name:  "ignore-paused",
 			Usage: "allow exec in a paused container",
 		},
+		cli.StringFlag{
+			Name:  "sched-policy",
+			Usage: "set the scheduling policy for the process (e.g. SCHED_BATCH or SCHED_IDLE)",
+		},
+		cli.IntFlag{
+			Name:  "nice",
+			Usage: "set the nice value for the process (from -20 to 19)",
+		},
+		cli.IntFlag{
+			Name:  "sched-priority",
+			Usage: "set the static scheduling priority for the process (from 1 to 99, for SCHED_FIFO or SCHED_RR)",
+		},
+		cli.StringFlag{
+			Name:  "cpu-affinity",
+			Usage: "set the CPU affinity for the process, as <initial>[:<final>] CPU lists (e.g. 0-3 or 0:0-3)",
+		},
+		cli.StringFlag{
+			Name:  "io-priority",
+			Usage: "set the I/O scheduling class and priority for the process, as <class>[:<priority>] (e.g. IOPRIO_CLASS_BE:7 or idle)",
+		},
 	},
 	Action: func(context *cli.Context) error {
 		if err := checkArgs(context, 1, minArgs); err != nil {
@@ -191,6 +211,26 @@ func execProcess(context *cli.Context) (int, error) {
 	return r.run(p)
 }
 
+// parseIOPriority parses the --io-priority value, in the form
+// <class>[:<priority>], where the IOPRIO_CLASS_ prefix of the class
+// can be omitted.
+func parseIOPriority(value string) (*specs.LinuxIOPriority, error) {
+	parts := strings.SplitN(value, ":", 2)
+	class := strings.ToUpper(parts[0])
+	if !strings.HasPrefix(class, "IOPRIO_CLASS_") {
+		class = "IOPRIO_CLASS_" + class
+	}
+	ioPriority := &specs.LinuxIOPriority{Class: specs.IOPriorityClass(class)}
+	if len(parts) == 2 {
+		p, err := strconv.Atoi(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid io priority %q: %w", value, err)
+		}
+		ioPriority.Priority = p
+	}
+	return ioPriority, nil
+}
+
 func getProcess(context *cli.Context, bundle string) (*specs.Process, error) {
 	if path := context.String("process"); path != "" {
 		f, err := os.Open(path)
@@ -244,6 +284,40 @@ func getProcess(context *cli.Context, bundle string) (*specs.Process, error) {
 	if context.IsSet("no-new-privs") {
 		p.NoNewPrivileges = context.Bool("no-new-privs")
 	}
+	if policy := context.String("sched-policy"); policy != "" {
+		// The scheduling attributes from the spec may not apply to the
+		// new policy, so start afresh.
+		policy = strings.ToUpper(policy)
+		if !strings.HasPrefix(policy, "SCHED_") {
+			policy = "SCHED_" + policy
+		}
+		p.Scheduler = &specs.Scheduler{Policy: specs.LinuxSchedulerPolicy(policy)}
+	}
+	if context.IsSet("nice") {
+		if p.Scheduler == nil {
+			p.Scheduler = &specs.Scheduler{Policy: specs.SchedOther}
+		}
+		p.Scheduler.Nice = int32(context.Int("nice"))
+	}
+	if context.IsSet("sched-priority") {
+		if p.Scheduler == nil {
+			return nil, errors.New("--sched-priority requires a SCHED_FIFO or SCHED_RR scheduling policy")
+		}
+		p.Scheduler.Priority = int32(context.Int("sched-priority"))
+	}
+	if aff := context.String("cpu-affinity"); aff != "" {
+		parts := strings.SplitN(aff, ":", 2)
+		p.ExecCPUAffinity = &specs.CPUAffinity{Initial: parts[0], Final: parts[0]}
+		if len(parts) == 2 {
+			p.ExecCPUAffinity.Final = parts[1]
+		}
+	}
+	if ioprio := context.String("io-priority"); ioprio != "" {
+		var err error
+		if p.IOPriority, err = parseIOPriority(ioprio); err != nil {
+			return nil, err
+		}
+	}
 	// override the user, if passed
 	if context.String("user") != "" {
 		u := strings.SplitN(context.String("user"), ":", 2)
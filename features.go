@@ -6,6 +6,7 @@ import (
 
 	"github.com/opencontainers/runc/libcontainer/capabilities"
 	"github.com/opencontainers/runc/libcontainer/configs"
+	"github.com/opencontainers/runc/libcontainer/landlock"
 	"github.com/opencontainers/runc/libcontainer/seccomp"
 	"github.com/opencontainers/runc/libcontainer/specconv"
 	"github.com/opencontainers/runc/types/features"
@@ -54,6 +55,16 @@ var featuresCommand = cli.Command{
 				Selinux: &features.Selinux{
 					Enabled: &tru,
 				},
+				Landlock: &features.Landlock{
+					Enabled:  &tru,
+					ABI:      landlock.ABIVersion(),
+					AccessFS: landlock.KnownAccessFS(),
+				},
+				MountExtensions: &features.MountExtensions{
+					IDMap: &features.IDMap{
+						Enabled: &tru,
+					},
+				},
 			},
 		}
 
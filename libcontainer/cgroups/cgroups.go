@@ -20,6 +20,11 @@ type Manager interface {
 	// GetStats returns cgroups statistics.
 	GetStats() (*Stats, error)
 
+	// FillStats resets stats and fills it with the statistics of the
+	// controllers selected by mask. Unlike GetStats, it only reads the
+	// files of these controllers, and allows to reuse stats.
+	FillStats(stats *Stats, mask StatsMask) error
+
 	// Freeze sets the freezer cgroup to the specified state.
 	Freeze(state configs.FreezerState) error
 
@@ -151,19 +151,30 @@ func (m *manager) Path(subsys string) string {
 }
 
 func (m *manager) GetStats() (*cgroups.Stats, error) {
+	stats := cgroups.NewStats()
+	if err := m.FillStats(stats, cgroups.StatsAll); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+func (m *manager) FillStats(stats *cgroups.Stats, mask cgroups.StatsMask) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
-	stats := cgroups.NewStats()
+	stats.Reset()
 	for _, sys := range subsystems {
+		if cgroups.SubsystemStats(sys.Name())&mask == 0 {
+			continue
+		}
 		path := m.paths[sys.Name()]
 		if path == "" {
 			continue
 		}
 		if err := sys.GetStats(path, stats); err != nil {
-			return nil, err
+			return err
 		}
 	}
-	return stats, nil
+	return nil
 }
 
 func (m *manager) Set(r *configs.Resources) error {
@@ -1,13 +1,17 @@
 package fs
 
 import (
+	"encoding/json"
+	"io"
 	"testing"
 
 	"github.com/opencontainers/runc/libcontainer/cgroups"
 	"github.com/opencontainers/runc/libcontainer/configs"
 )
 
-func BenchmarkGetStats(b *testing.B) {
+// newBenchmarkManager returns the manager of a cgroup created for the
+// benchmark b, in the real cgroupfs.
+func newBenchmarkManager(b *testing.B) cgroups.Manager {
 	if cgroups.IsCgroup2UnifiedMode() {
 		b.Skip("cgroup v2 is not supported")
 	}
@@ -15,9 +19,9 @@ func BenchmarkGetStats(b *testing.B) {
 	// Unset TestMode as we work with real cgroupfs here,
 	// and we want OpenFile to perform the fstype check.
 	cgroups.TestMode = false
-	defer func() {
+	b.Cleanup(func() {
 		cgroups.TestMode = true
-	}()
+	})
 
 	cg := &configs.Cgroup{
 		Path:      "/some/kind/of/a/path/here",
@@ -31,20 +35,63 @@ func BenchmarkGetStats(b *testing.B) {
 	if err != nil {
 		b.Fatal(err)
 	}
-	defer func() {
+	b.Cleanup(func() {
 		_ = m.Destroy()
-	}()
+	})
+	return m
+}
 
-	var st *cgroups.Stats
+// The stats benchmarks also encode the stats, like runc events does, so
+// that the whole path is measured.
+func BenchmarkGetStats(b *testing.B) {
+	m := newBenchmarkManager(b)
 
+	var (
+		st  *cgroups.Stats
+		err error
+	)
+
+	enc := json.NewEncoder(io.Discard)
+	b.ReportAllocs()
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
 		st, err = m.GetStats()
 		if err != nil {
 			b.Fatal(err)
 		}
+		if err := enc.Encode(st); err != nil {
+			b.Fatal(err)
+		}
 	}
 	if st.CpuStats.CpuUsage.TotalUsage != 0 {
 		b.Fatalf("stats: %+v", st)
 	}
 }
+
+func BenchmarkFillStats(b *testing.B) {
+	m := newBenchmarkManager(b)
+
+	for _, bc := range []struct {
+		name string
+		mask cgroups.StatsMask
+	}{
+		{"all", cgroups.StatsAll},
+		{"memory,cpu", cgroups.StatsMemory | cgroups.StatsCPU},
+		{"pids", cgroups.StatsPids},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			st := cgroups.NewStats()
+			enc := json.NewEncoder(io.Discard)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if err := m.FillStats(st, bc.mask); err != nil {
+					b.Fatal(err)
+				}
+				if err := enc.Encode(st); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
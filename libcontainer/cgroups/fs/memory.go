@@ -181,6 +181,14 @@ func (s *MemoryGroup) GetStats(path string, stats *cgroups.Stats) error {
 	}
 	stats.MemoryStats.KernelTCPUsage = kernelTCPUsage
 
+	// cgroup v1 has no memory.events, so provide what is available.
+	stats.MemoryStats.Events.Max = memoryUsage.Failcnt
+	oomKill, err := fscommon.GetValueByKey(path, "memory.oom_control", "oom_kill")
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	stats.MemoryStats.Events.OOMKill = oomKill
+
 	value, err := fscommon.GetCgroupParamUint(path, "memory.use_hierarchy")
 	if err != nil {
 		return err
@@ -2,6 +2,7 @@ package fs
 
 import (
 	"math"
+	"os"
 	"strconv"
 
 	"github.com/opencontainers/runc/libcontainer/cgroups"
@@ -58,5 +59,18 @@ func (s *PidsGroup) GetStats(path string, stats *cgroups.Stats) error {
 
 	stats.PidsStats.Current = current
 	stats.PidsStats.Limit = max
+
+	// pids.peak is only available since kernel 6.1.
+	peak, err := fscommon.GetCgroupParamUint(path, "pids.peak")
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	stats.PidsStats.Peak = peak
+
+	maxEvents, err := fscommon.GetValueByKey(path, "pids.events", "max")
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	stats.PidsStats.MaxEvents = maxEvents
 	return nil
 }
@@ -107,3 +107,28 @@ func TestPidsStatsUnlimited(t *testing.T) {
 		t.Fatalf("Expected %d, got %d for pids.max", 0, stats.PidsStats.Limit)
 	}
 }
+
+func TestPidsStatsPeakAndEvents(t *testing.T) {
+	path := tempDir(t, "pids")
+
+	writeFileContents(t, path, map[string]string{
+		"pids.current": strconv.Itoa(10),
+		"pids.max":     strconv.Itoa(maxLimited),
+		"pids.peak":    strconv.Itoa(maxLimited),
+		"pids.events":  "max 42\n",
+	})
+
+	pids := &PidsGroup{}
+	stats := *cgroups.NewStats()
+	if err := pids.GetStats(path, &stats); err != nil {
+		t.Fatal(err)
+	}
+
+	if stats.PidsStats.Peak != maxLimited {
+		t.Fatalf("Expected %d, got %d for pids.peak", maxLimited, stats.PidsStats.Peak)
+	}
+
+	if stats.PidsStats.MaxEvents != 42 {
+		t.Fatalf("Expected %d, got %d for pids.events max", 42, stats.PidsStats.MaxEvents)
+	}
+}
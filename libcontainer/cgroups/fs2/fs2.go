@@ -93,39 +93,73 @@ func (m *manager) GetAllPids() ([]int, error) {
 }
 
 func (m *manager) GetStats() (*cgroups.Stats, error) {
+	st := cgroups.NewStats()
+	return st, m.FillStats(st, cgroups.StatsAll)
+}
+
+func (m *manager) FillStats(st *cgroups.Stats, mask cgroups.StatsMask) error {
 	var errs []error
 
-	st := cgroups.NewStats()
+	st.Reset()
 
 	// pids (since kernel 4.5)
-	if err := statPids(m.dirPath, st); err != nil {
-		errs = append(errs, err)
+	if mask&cgroups.StatsPids != 0 {
+		if err := statPids(m.dirPath, st); err != nil {
+			errs = append(errs, err)
+		}
 	}
 	// memory (since kernel 4.5)
-	if err := statMemory(m.dirPath, st); err != nil && !os.IsNotExist(err) {
-		errs = append(errs, err)
+	if mask&cgroups.StatsMemory != 0 {
+		if err := statMemory(m.dirPath, st); err != nil && !os.IsNotExist(err) {
+			errs = append(errs, err)
+		}
 	}
 	// io (since kernel 4.5)
-	if err := statIo(m.dirPath, st); err != nil && !os.IsNotExist(err) {
-		errs = append(errs, err)
+	if mask&cgroups.StatsBlkio != 0 {
+		if err := statIo(m.dirPath, st); err != nil && !os.IsNotExist(err) {
+			errs = append(errs, err)
+		}
 	}
 	// cpu (since kernel 4.15)
 	// Note cpu.stat is available even if the controller is not enabled.
-	if err := statCpu(m.dirPath, st); err != nil && !os.IsNotExist(err) {
-		errs = append(errs, err)
+	if mask&cgroups.StatsCPU != 0 {
+		if err := statCpu(m.dirPath, st); err != nil && !os.IsNotExist(err) {
+			errs = append(errs, err)
+		}
 	}
 	// hugetlb (since kernel 5.6)
-	if err := statHugeTlb(m.dirPath, st); err != nil && !os.IsNotExist(err) {
-		errs = append(errs, err)
+	if mask&cgroups.StatsHugetlb != 0 {
+		if err := statHugeTlb(m.dirPath, st); err != nil && !os.IsNotExist(err) {
+			errs = append(errs, err)
+		}
 	}
 	// rdma (since kernel 4.11)
-	if err := fscommon.RdmaGetStats(m.dirPath, st); err != nil && !os.IsNotExist(err) {
-		errs = append(errs, err)
+	if mask&cgroups.StatsRdma != 0 {
+		if err := fscommon.RdmaGetStats(m.dirPath, st); err != nil && !os.IsNotExist(err) {
+			errs = append(errs, err)
+		}
+	}
+	// PSI (since kernel 4.20)
+	var err error
+	if mask&cgroups.StatsCPU != 0 {
+		if st.CpuStats.PSI, err = statPSI(m.dirPath, "cpu.pressure"); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if mask&cgroups.StatsMemory != 0 {
+		if st.MemoryStats.PSI, err = statPSI(m.dirPath, "memory.pressure"); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if mask&cgroups.StatsBlkio != 0 {
+		if st.BlkioStats.PSI, err = statPSI(m.dirPath, "io.pressure"); err != nil {
+			errs = append(errs, err)
+		}
 	}
 	if len(errs) > 0 && !m.config.Rootless {
-		return st, fmt.Errorf("error while statting cgroup v2: %+v", errs)
+		return fmt.Errorf("error while statting cgroup v2: %+v", errs)
 	}
-	return st, nil
+	return nil
 }
 
 func (m *manager) Freeze(state configs.FreezerState) error {
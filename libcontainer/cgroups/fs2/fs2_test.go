@@ -0,0 +1,115 @@
+package fs2
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/opencontainers/runc/libcontainer/cgroups"
+	"github.com/opencontainers/runc/libcontainer/configs"
+)
+
+// fakeStatsCgroup returns a manager of a fake cgroup with the files read to
+// collect the stats.
+func fakeStatsCgroup(tb testing.TB) cgroups.Manager {
+	// We're using a fake cgroupfs.
+	cgroups.TestMode = true
+
+	dir := tb.TempDir()
+	const psi = "some avg10=0.00 avg60=0.00 avg300=0.00 total=0\nfull avg10=0.00 avg60=0.00 avg300=0.00 total=0\n"
+	for file, content := range map[string]string{
+		"cgroup.procs":        "1\n",
+		"pids.current":        "1\n",
+		"pids.max":            "max\n",
+		"memory.current":      "1048576\n",
+		"memory.max":          "max\n",
+		"memory.peak":         "2097152\n",
+		"memory.stat":         "anon 524288\nfile 524288\ninactive_file 262144\n",
+		"memory.events":       "low 0\nhigh 0\nmax 0\noom 0\noom_kill 0\n",
+		"memory.swap.current": "0\n",
+		"memory.swap.max":     "max\n",
+		"io.stat":             "8:0 rbytes=4096 wbytes=8192 rios=1 wios=2 dbytes=0 dios=0\n",
+		"cpu.stat":            "usage_usec 1000\nuser_usec 600\nsystem_usec 400\n",
+		"cpu.pressure":        psi,
+		"memory.pressure":     psi,
+		"io.pressure":         psi,
+	} {
+		if err := os.WriteFile(filepath.Join(dir, file), []byte(content), 0o644); err != nil {
+			tb.Fatal(err)
+		}
+	}
+	m, err := NewManager(&configs.Cgroup{Resources: &configs.Resources{}}, dir)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return m
+}
+
+func TestFillStats(t *testing.T) {
+	m := fakeStatsCgroup(t)
+	st := cgroups.NewStats()
+	if err := m.FillStats(st, cgroups.StatsAll); err != nil {
+		t.Fatal(err)
+	}
+	if st.PidsStats.Current != 1 || st.MemoryStats.Usage.Usage != 1048576 || st.CpuStats.CpuUsage.TotalUsage != 1000000 {
+		t.Fatalf("unexpected stats: %+v", st)
+	}
+
+	// The stats of the controllers left out of the mask are cleared.
+	if err := m.FillStats(st, cgroups.StatsMemory); err != nil {
+		t.Fatal(err)
+	}
+	if st.PidsStats.Current != 0 || st.CpuStats.CpuUsage.TotalUsage != 0 || st.CpuStats.PSI != nil || len(st.BlkioStats.IoServiceBytesRecursive) != 0 {
+		t.Errorf("expected only memory stats, got %+v", st)
+	}
+	if st.MemoryStats.Usage.Usage != 1048576 || st.MemoryStats.Stats["anon"] != 524288 || st.MemoryStats.PSI == nil {
+		t.Errorf("expected memory stats, got %+v", st.MemoryStats)
+	}
+}
+
+// The stats benchmarks also encode the stats, like runc events does, so
+// that the whole path is measured.
+func BenchmarkGetStats(b *testing.B) {
+	m := fakeStatsCgroup(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	enc := json.NewEncoder(io.Discard)
+	for i := 0; i < b.N; i++ {
+		st, err := m.GetStats()
+		if err != nil {
+			b.Fatal(err)
+		}
+		if err := enc.Encode(st); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkFillStats(b *testing.B) {
+	m := fakeStatsCgroup(b)
+	for _, bc := range []struct {
+		name string
+		mask cgroups.StatsMask
+	}{
+		{"all", cgroups.StatsAll},
+		{"memory,cpu", cgroups.StatsMemory | cgroups.StatsCPU},
+		{"pids", cgroups.StatsPids},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			st := cgroups.NewStats()
+			enc := json.NewEncoder(io.Discard)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if err := m.FillStats(st, bc.mask); err != nil {
+					b.Fatal(err)
+				}
+				if err := enc.Encode(st); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -32,8 +32,24 @@ func numToStr(value int64) (ret string) {
 	return ret
 }
 
+// ptrToStr converts an optional int64 value to a string for writing to a
+// cgroupv2 file. Unlike numToStr, 0 is a valid value, and nil means the
+// value is not set. The value of -1 is converted to "max".
+func ptrToStr(value *int64) string {
+	switch {
+	case value == nil:
+		return ""
+	case *value == -1:
+		return "max"
+	default:
+		return strconv.FormatInt(*value, 10)
+	}
+}
+
 func isMemorySet(r *configs.Resources) bool {
-	return r.MemoryReservation != 0 || r.Memory != 0 || r.MemorySwap != 0
+	return r.MemoryReservation != 0 || r.Memory != 0 || r.MemorySwap != 0 ||
+		r.MemoryHigh != nil || r.MemoryMin != nil || r.MemoryOOMGroup != nil ||
+		r.MemorySwapHigh != nil || r.MemoryZswapMax != nil
 }
 
 func setMemory(dirPath string, r *configs.Resources) error {
@@ -70,6 +86,32 @@ func setMemory(dirPath string, r *configs.Resources) error {
 		}
 	}
 
+	for _, f := range []struct {
+		name  string
+		value *int64
+	}{
+		{"memory.high", r.MemoryHigh},
+		{"memory.min", r.MemoryMin},
+		{"memory.swap.high", r.MemorySwapHigh},
+		{"memory.zswap.max", r.MemoryZswapMax},
+	} {
+		if val := ptrToStr(f.value); val != "" {
+			if err := cgroups.WriteFile(dirPath, f.name, val); err != nil {
+				return err
+			}
+		}
+	}
+
+	if r.MemoryOOMGroup != nil {
+		val := "0"
+		if *r.MemoryOOMGroup {
+			val = "1"
+		}
+		if err := cgroups.WriteFile(dirPath, "memory.oom.group", val); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -120,6 +162,40 @@ func statMemory(dirPath string, stats *cgroups.Stats) error {
 	}
 	stats.MemoryStats.SwapUsage = swapUsage
 
+	return statMemoryEvents(dirPath, stats)
+}
+
+func statMemoryEvents(dirPath string, stats *cgroups.Stats) error {
+	const file = "memory.events"
+	eventsFile, err := cgroups.OpenFile(dirPath, file, os.O_RDONLY)
+	if err != nil {
+		return err
+	}
+	defer eventsFile.Close()
+
+	events := &stats.MemoryStats.Events
+	counters := map[string]*uint64{
+		"low":            &events.Low,
+		"high":           &events.High,
+		"max":            &events.Max,
+		"oom":            &events.OOM,
+		"oom_kill":       &events.OOMKill,
+		"oom_group_kill": &events.OOMGroupKill,
+	}
+	sc := bufio.NewScanner(eventsFile)
+	for sc.Scan() {
+		k, v, err := fscommon.ParseKeyValue(sc.Text())
+		if err != nil {
+			return &parseError{Path: dirPath, File: file, Err: err}
+		}
+		if p, ok := counters[k]; ok {
+			*p = v
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return &parseError{Path: dirPath, File: file, Err: err}
+	}
+
 	return nil
 }
 
@@ -151,6 +227,15 @@ func getMemoryDataV2(path, name string) (cgroups.MemoryData, error) {
 	}
 	memoryData.Limit = value
 
+	if name == "" {
+		// memory.peak is only available since kernel 5.19.
+		value, err = fscommon.GetCgroupParamUint(path, "memory.peak")
+		if err != nil && !os.IsNotExist(err) {
+			return cgroups.MemoryData{}, err
+		}
+		memoryData.MaxUsage = value
+	}
+
 	return memoryData, nil
 }
 
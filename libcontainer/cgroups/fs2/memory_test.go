@@ -0,0 +1,79 @@
+package fs2
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/opencontainers/runc/libcontainer/cgroups"
+	"github.com/opencontainers/runc/libcontainer/configs"
+)
+
+func TestSetMemoryV2Only(t *testing.T) {
+	// We're using a fake cgroupfs.
+	cgroups.TestMode = true
+
+	fakeCgroupDir := t.TempDir()
+	high, min, swapHigh := int64(1<<20), int64(0), int64(-1)
+	oomGroup := true
+	r := &configs.Resources{
+		MemoryHigh:     &high,
+		MemoryMin:      &min,
+		MemorySwapHigh: &swapHigh,
+		MemoryOOMGroup: &oomGroup,
+	}
+	if err := setMemory(fakeCgroupDir, r); err != nil {
+		t.Fatal(err)
+	}
+
+	for file, expected := range map[string]string{
+		"memory.high":      "1048576",
+		"memory.min":       "0",
+		"memory.swap.high": "max",
+		"memory.oom.group": "1",
+	} {
+		value, err := os.ReadFile(filepath.Join(fakeCgroupDir, file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(value) != expected {
+			t.Errorf("expected %s to be %q, got %q", file, expected, value)
+		}
+	}
+
+	// Unset values must not be written.
+	for _, file := range []string{"memory.max", "memory.low", "memory.swap.max", "memory.zswap.max"} {
+		if _, err := os.Stat(filepath.Join(fakeCgroupDir, file)); !os.IsNotExist(err) {
+			t.Errorf("expected %s not to be written, got %v", file, err)
+		}
+	}
+}
+
+func TestStatMemoryEvents(t *testing.T) {
+	const exampleMemoryEvents = `low 1
+high 2
+max 3
+oom 4
+oom_kill 5
+oom_group_kill 6
+unknown 7
+`
+
+	// We're using a fake cgroupfs.
+	cgroups.TestMode = true
+
+	fakeCgroupDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(fakeCgroupDir, "memory.events"), []byte(exampleMemoryEvents), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	st := cgroups.NewStats()
+	if err := statMemoryEvents(fakeCgroupDir, st); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := cgroups.MemoryEvents{Low: 1, High: 2, Max: 3, OOM: 4, OOMKill: 5, OOMGroupKill: 6}
+	if st.MemoryStats.Events != expected {
+		t.Errorf("expected memory events %+v, got %+v", expected, st.MemoryStats.Events)
+	}
+}
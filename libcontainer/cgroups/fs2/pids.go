@@ -68,5 +68,18 @@ func statPids(dirPath string, stats *cgroups.Stats) error {
 
 	stats.PidsStats.Current = current
 	stats.PidsStats.Limit = max
+
+	// pids.peak is only available since kernel 6.1.
+	peak, err := fscommon.GetCgroupParamUint(dirPath, "pids.peak")
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	stats.PidsStats.Peak = peak
+
+	maxEvents, err := fscommon.GetValueByKey(dirPath, "pids.events", "max")
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	stats.PidsStats.MaxEvents = maxEvents
 	return nil
 }
@@ -32,9 +32,28 @@ type CpuUsage struct {
 	UsageInUsermode uint64 `json:"usage_in_usermode"`
 }
 
+// PSIData is one line of a cgroup v2 pressure file: the share of time some
+// (or all) tasks were stalled, averaged over 10, 60 and 300 seconds, and the
+// total stall time.
+type PSIData struct {
+	Avg10  float64 `json:"avg10"`
+	Avg60  float64 `json:"avg60"`
+	Avg300 float64 `json:"avg300"`
+	// Units: microseconds.
+	Total uint64 `json:"total"`
+}
+
+// PSIStats holds the Pressure Stall Information of a resource.
+// See https://www.kernel.org/doc/html/latest/accounting/psi.html.
+type PSIStats struct {
+	Some PSIData `json:"some,omitempty"`
+	Full PSIData `json:"full,omitempty"`
+}
+
 type CpuStats struct {
 	CpuUsage       CpuUsage       `json:"cpu_usage,omitempty"`
 	ThrottlingData ThrottlingData `json:"throttling_data,omitempty"`
+	PSI            *PSIStats      `json:"psi,omitempty"`
 }
 
 type CPUSetStats struct {
@@ -71,6 +90,25 @@ type MemoryData struct {
 	Limit    uint64 `json:"limit"`
 }
 
+// MemoryEvents holds the memory.events counters of a cgroup v2, or their
+// cgroup v1 equivalents where those exist.
+type MemoryEvents struct {
+	// number of times the usage was under the low boundary, but was
+	// reclaimed anyway
+	Low uint64 `json:"low,omitempty"`
+	// number of times the usage was throttled as it exceeded the high boundary
+	High uint64 `json:"high,omitempty"`
+	// number of times the usage was about to go over the max boundary
+	// (on cgroup v1, memory.failcnt)
+	Max uint64 `json:"max,omitempty"`
+	// number of times the usage reached the limit and allocation failed
+	OOM uint64 `json:"oom,omitempty"`
+	// number of processes killed by the OOM killer
+	OOMKill uint64 `json:"oom_kill,omitempty"`
+	// number of times the whole cgroup was killed by the OOM killer
+	OOMGroupKill uint64 `json:"oom_group_kill,omitempty"`
+}
+
 type MemoryStats struct {
 	// memory used for cache
 	Cache uint64 `json:"cache,omitempty"`
@@ -88,7 +126,9 @@ type MemoryStats struct {
 	// if true, memory usage is accounted for throughout a hierarchy of cgroups.
 	UseHierarchy bool `json:"use_hierarchy"`
 
-	Stats map[string]uint64 `json:"stats,omitempty"`
+	Stats  map[string]uint64 `json:"stats,omitempty"`
+	Events MemoryEvents      `json:"events,omitempty"`
+	PSI    *PSIStats         `json:"psi,omitempty"`
 }
 
 type PageUsageByNUMA struct {
@@ -114,6 +154,11 @@ type PidsStats struct {
 	Current uint64 `json:"current,omitempty"`
 	// active pids hard limit
 	Limit uint64 `json:"limit,omitempty"`
+	// maximum number of pids ever recorded (if supported by the kernel)
+	Peak uint64 `json:"peak,omitempty"`
+	// number of times a fork failed because of the limit
+	// (the max counter from pids.events)
+	MaxEvents uint64 `json:"max_events,omitempty"`
 }
 
 type BlkioStatEntry struct {
@@ -133,6 +178,7 @@ type BlkioStats struct {
 	IoMergedRecursive       []BlkioStatEntry `json:"io_merged_recursive,omitempty"`
 	IoTimeRecursive         []BlkioStatEntry `json:"io_time_recursive,omitempty"`
 	SectorsRecursive        []BlkioStatEntry `json:"sectors_recursive,omitempty"`
+	PSI                     *PSIStats        `json:"psi,omitempty"`
 }
 
 type HugetlbStats struct {
@@ -171,3 +217,81 @@ func NewStats() *Stats {
 	hugetlbStats := make(map[string]HugetlbStats)
 	return &Stats{MemoryStats: memoryStats, HugetlbStats: hugetlbStats}
 }
+
+// Reset clears s, so that it can be reused to collect statistics, while
+// keeping the memory allocated for its maps and slices. The maps and slices
+// previously returned in s are overwritten.
+func (s *Stats) Reset() {
+	memStats, hugetlb := s.MemoryStats.Stats, s.HugetlbStats
+	if memStats == nil {
+		memStats = make(map[string]uint64)
+	}
+	for k := range memStats {
+		delete(memStats, k)
+	}
+	if hugetlb == nil {
+		hugetlb = make(map[string]HugetlbStats)
+	}
+	for k := range hugetlb {
+		delete(hugetlb, k)
+	}
+	blkio := s.BlkioStats
+	*s = Stats{
+		MemoryStats:  MemoryStats{Stats: memStats},
+		HugetlbStats: hugetlb,
+		BlkioStats: BlkioStats{
+			IoServiceBytesRecursive: blkio.IoServiceBytesRecursive[:0],
+			IoServicedRecursive:     blkio.IoServicedRecursive[:0],
+			IoQueuedRecursive:       blkio.IoQueuedRecursive[:0],
+			IoServiceTimeRecursive:  blkio.IoServiceTimeRecursive[:0],
+			IoWaitTimeRecursive:     blkio.IoWaitTimeRecursive[:0],
+			IoMergedRecursive:       blkio.IoMergedRecursive[:0],
+			IoTimeRecursive:         blkio.IoTimeRecursive[:0],
+			SectorsRecursive:        blkio.SectorsRecursive[:0],
+		},
+	}
+}
+
+// StatsMask selects the controllers to collect statistics from.
+type StatsMask uint32
+
+const (
+	// StatsCPU selects the cpu and cpuacct statistics, and the CPU
+	// pressure on cgroup v2.
+	StatsCPU StatsMask = 1 << iota
+	// StatsCPUSet selects the cpuset statistics.
+	StatsCPUSet
+	// StatsMemory selects the memory statistics, and the memory pressure
+	// on cgroup v2.
+	StatsMemory
+	// StatsPids selects the pids statistics.
+	StatsPids
+	// StatsBlkio selects the blkio (or io, on cgroup v2) statistics, and
+	// the I/O pressure on cgroup v2.
+	StatsBlkio
+	// StatsHugetlb selects the hugetlb statistics.
+	StatsHugetlb
+	// StatsRdma selects the rdma statistics.
+	StatsRdma
+
+	// StatsAll selects the statistics of all the controllers.
+	StatsAll = StatsCPU | StatsCPUSet | StatsMemory | StatsPids | StatsBlkio | StatsHugetlb | StatsRdma
+)
+
+// subsystemStats are the statistics the cgroup v1 subsystems report.
+var subsystemStats = map[string]StatsMask{
+	"cpu":     StatsCPU,
+	"cpuacct": StatsCPU,
+	"cpuset":  StatsCPUSet,
+	"memory":  StatsMemory,
+	"pids":    StatsPids,
+	"blkio":   StatsBlkio,
+	"hugetlb": StatsHugetlb,
+	"rdma":    StatsRdma,
+}
+
+// SubsystemStats returns the statistics the cgroup v1 subsystem reports,
+// or 0 if it does not report any.
+func SubsystemStats(subsystem string) StatsMask {
+	return subsystemStats[subsystem]
+}
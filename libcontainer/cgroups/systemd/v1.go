@@ -320,20 +320,30 @@ func (m *legacyManager) GetAllPids() ([]int, error) {
 }
 
 func (m *legacyManager) GetStats() (*cgroups.Stats, error) {
+	stats := cgroups.NewStats()
+	if err := m.FillStats(stats, cgroups.StatsAll); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+func (m *legacyManager) FillStats(stats *cgroups.Stats, mask cgroups.StatsMask) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
-	stats := cgroups.NewStats()
+	stats.Reset()
 	for _, sys := range legacySubsystems {
+		if cgroups.SubsystemStats(sys.Name())&mask == 0 {
+			continue
+		}
 		path := m.paths[sys.Name()]
 		if path == "" {
 			continue
 		}
 		if err := sys.GetStats(path, stats); err != nil {
-			return nil, err
+			return err
 		}
 	}
-
-	return stats, nil
+	return nil
 }
 
 // freezeBeforeSet answers whether there is a need to freeze the cgroup before
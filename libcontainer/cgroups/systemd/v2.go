@@ -173,6 +173,15 @@ func unifiedResToSystemdProps(cm *dbusConnManager, res map[string]string) (props
 	return props, nil
 }
 
+// memoryPropValue converts a memory limit value from configs.Resources
+// to a systemd unit property value, with -1 meaning "infinity".
+func memoryPropValue(value int64) uint64 {
+	if value == -1 {
+		return math.MaxUint64
+	}
+	return uint64(value)
+}
+
 func genV2ResourcesProperties(r *configs.Resources, cm *dbusConnManager) ([]systemdDbus.Property, error) {
 	var properties []systemdDbus.Property
 
@@ -204,6 +213,27 @@ func genV2ResourcesProperties(r *configs.Resources, cm *dbusConnManager) ([]syst
 		properties = append(properties,
 			newProp("MemorySwapMax", uint64(swap)))
 	}
+	if r.MemoryHigh != nil {
+		properties = append(properties,
+			newProp("MemoryHigh", memoryPropValue(*r.MemoryHigh)))
+	}
+	if r.MemoryMin != nil {
+		properties = append(properties,
+			newProp("MemoryMin", memoryPropValue(*r.MemoryMin)))
+	}
+	if r.MemoryZswapMax != nil {
+		// systemd only supports this property since v253
+		if sdVer := systemdVersion(cm); sdVer >= 253 {
+			properties = append(properties,
+				newProp("MemoryZSwapMax", memoryPropValue(*r.MemoryZswapMax)))
+		} else {
+			logrus.Debugf("systemd v%d is too old to support MemoryZSwapMax"+
+				" (setting will still be applied to cgroupfs)", sdVer)
+		}
+	}
+	// r.MemorySwapHigh has no systemd equivalent, and r.MemoryOOMGroup
+	// is not converted for the same reason as unified memory.oom.group
+	// (see unifiedResToSystemdProps). Both are still applied to cgroupfs.
 
 	if r.CpuWeight != 0 {
 		properties = append(properties,
@@ -423,6 +453,10 @@ func (m *unifiedManager) GetStats() (*cgroups.Stats, error) {
 	return m.fsMgr.GetStats()
 }
 
+func (m *unifiedManager) FillStats(stats *cgroups.Stats, mask cgroups.StatsMask) error {
+	return m.fsMgr.FillStats(stats, mask)
+}
+
 func (m *unifiedManager) Set(r *configs.Resources) error {
 	if r == nil {
 		return nil
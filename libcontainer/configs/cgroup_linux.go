@@ -134,6 +134,26 @@ type Resources struct {
 	// CpuWeight sets a proportional bandwidth limit.
 	CpuWeight uint64 `json:"cpu_weight"`
 
+	// MemoryHigh is the memory usage throttle limit (in bytes), written
+	// to memory.high. Set to -1 to remove the limit.
+	MemoryHigh *int64 `json:"memory_high,omitempty"`
+
+	// MemoryMin is the amount of memory (in bytes) protected from
+	// reclaim, written to memory.min.
+	MemoryMin *int64 `json:"memory_min,omitempty"`
+
+	// MemoryOOMGroup tells the OOM killer to treat the cgroup as an
+	// indivisible workload, i.e. to kill all of its tasks together.
+	MemoryOOMGroup *bool `json:"memory_oom_group,omitempty"`
+
+	// MemorySwapHigh is the swap usage throttle limit (in bytes), written
+	// to memory.swap.high. Set to -1 to remove the limit.
+	MemorySwapHigh *int64 `json:"memory_swap_high,omitempty"`
+
+	// MemoryZswapMax is the zswap usage hard limit (in bytes), written
+	// to memory.zswap.max. Set to -1 to remove the limit.
+	MemoryZswapMax *int64 `json:"memory_zswap_max,omitempty"`
+
 	// Unified is cgroupv2-only key-value map.
 	Unified map[string]string `json:"unified"`
 
@@ -37,6 +37,10 @@ type Seccomp struct {
 	DefaultErrnoRet  *uint      `json:"default_errno_ret"`
 	ListenerPath     string     `json:"listener_path,omitempty"`
 	ListenerMetadata string     `json:"listener_metadata,omitempty"`
+	// Recording is only set on the profile recording the system calls made
+	// by the container, the only one which can have a SCMP_ACT_NOTIFY
+	// default action.
+	Recording bool `json:"recording,omitempty"`
 }
 
 // Action is taken upon rule match in Seccomp
@@ -144,6 +148,10 @@ type Config struct {
 	// commonly used by selinux
 	ProcessLabel string `json:"process_label,omitempty"`
 
+	// Landlock specifies the Landlock ruleset to apply to the processes running in the container
+	// right before they are execed
+	Landlock *Landlock `json:"landlock,omitempty"`
+
 	// Rlimits specifies the resource limits, such as max open files, to set in the container
 	// If Rlimits are not set, the container will inherit rlimits from the parent process
 	Rlimits []Rlimit `json:"rlimits,omitempty"`
@@ -208,8 +216,100 @@ type Config struct {
 	// RootlessCgroups is set when unlikely to have the full access to cgroups.
 	// When RootlessCgroups is set, cgroups errors are ignored.
 	RootlessCgroups bool `json:"rootless_cgroups,omitempty"`
+
+	// TimeOffsets specifies the offsets of the monotonic and boottime clocks
+	// inside a new time namespace, keyed by clock name.
+	TimeOffsets map[string]specs.LinuxTimeOffset `json:"time_offsets,omitempty"`
+
+	// Scheduler specifies the scheduling policy and attributes of the processes
+	// in the container, set with sched_setattr(2).
+	Scheduler *Scheduler `json:"scheduler,omitempty"`
+
+	// IOPriority specifies the I/O scheduling class and priority of the processes
+	// in the container, set with ioprio_set(2).
+	IOPriority *IOPriority `json:"io_priority,omitempty"`
+
+	// ExecCPUAffinity specifies the CPU affinity of the processes executed in the
+	// container (but not of the container init).
+	ExecCPUAffinity *CPUAffinity `json:"exec_cpu_affinity,omitempty"`
+
+	// Personality specifies the execution domain of the processes in the container,
+	// set with personality(2).
+	Personality *LinuxPersonality `json:"personality,omitempty"`
+}
+
+// Execution domains, see personality(2).
+const (
+	PerLinux   = 0x0000
+	PerLinux32 = 0x0008
+)
+
+// LinuxPersonality represents the personality(2) syscall input.
+type LinuxPersonality struct {
+	// Domain is the execution domain, either PerLinux or PerLinux32.
+	Domain int `json:"domain"`
+
+	// Flags are additional personality flags to apply to the domain.
+	Flags []int `json:"flags,omitempty"`
+}
+
+// Scheduler is based on the Linux sched_setattr(2) syscall.
+type Scheduler struct {
+	// Policy represents the scheduling policy (e.g., SCHED_FIFO, SCHED_RR, SCHED_OTHER).
+	Policy specs.LinuxSchedulerPolicy `json:"policy"`
+
+	// Nice is the nice value for the process, which affects its priority.
+	Nice int32 `json:"nice,omitempty"`
+
+	// Priority represents the static priority of the process.
+	Priority int32 `json:"priority,omitempty"`
+
+	// Flags is an array of scheduling flags.
+	Flags []specs.LinuxSchedulerFlag `json:"flags,omitempty"`
+
+	// The following ones are used by the DEADLINE scheduler.
+
+	// Runtime is the amount of time in nanoseconds during which the process
+	// is allowed to run in a given period.
+	Runtime uint64 `json:"runtime,omitempty"`
+
+	// Deadline is the absolute deadline for the process to complete its execution.
+	Deadline uint64 `json:"deadline,omitempty"`
+
+	// Period is the length of the period in nanoseconds used for determining the process runtime.
+	Period uint64 `json:"period,omitempty"`
+
+	// UtilMin and UtilMax are the utilization clamp values (from 0 to 1024),
+	// used with the SCHED_FLAG_UTIL_CLAMP_MIN and SCHED_FLAG_UTIL_CLAMP_MAX
+	// flags. The runtime spec has no fields for them.
+	UtilMin uint32 `json:"util_min,omitempty"`
+	UtilMax uint32 `json:"util_max,omitempty"`
 }
 
+// NewScheduler converts the scheduler settings of the runtime spec.
+func NewScheduler(s *specs.Scheduler) *Scheduler {
+	if s == nil {
+		return nil
+	}
+	return &Scheduler{
+		Policy:   s.Policy,
+		Nice:     s.Nice,
+		Priority: s.Priority,
+		Flags:    s.Flags,
+		Runtime:  s.Runtime,
+		Deadline: s.Deadline,
+		Period:   s.Period,
+	}
+}
+
+// IOPriority is based on the Linux ioprio_set(2) syscall.
+type IOPriority = specs.LinuxIOPriority
+
+// CPUAffinity holds the CPU lists (such as "0-3,7") of the initial CPU
+// affinity of a process, used while it joins the container, and of its
+// final CPU affinity, set once it is in the container cgroup.
+type CPUAffinity = specs.CPUAffinity
+
 type (
 	HookName string
 	HookList []Hook
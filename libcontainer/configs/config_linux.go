@@ -1,6 +1,18 @@
 package configs
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+
+	"github.com/opencontainers/runc/libcontainer/system"
+)
 
 var (
 	errNoUIDMap   = errors.New("User namespaces enabled, but no uid mappings found.")
@@ -66,3 +78,108 @@ func (c Config) hostIDFromMapping(containerID int, uMap []IDMap) (int, bool) {
 	}
 	return -1, false
 }
+
+var schedPolicies = map[specs.LinuxSchedulerPolicy]uint32{
+	specs.SchedOther:    0,
+	specs.SchedFIFO:     1,
+	specs.SchedRR:       2,
+	specs.SchedBatch:    3,
+	specs.SchedISO:      4,
+	specs.SchedIdle:     5,
+	specs.SchedDeadline: 6,
+}
+
+var schedFlags = map[specs.LinuxSchedulerFlag]uint64{
+	specs.SchedFlagResetOnFork:  0x01,
+	specs.SchedFlagReclaim:      0x02,
+	specs.SchedFlagDLOverrun:    0x04,
+	specs.SchedFlagKeepPolicy:   0x08,
+	specs.SchedFlagKeepParams:   0x10,
+	specs.SchedFlagUtilClampMin: 0x20,
+	specs.SchedFlagUtilClampMax: 0x40,
+}
+
+// ToCPUSet parses a CPU list, such as "0-3,7", to a CPU set. An empty list
+// results in a nil CPU set.
+func ToCPUSet(list string) (*unix.CPUSet, error) {
+	if list == "" {
+		return nil, nil
+	}
+	var set unix.CPUSet
+	maxCPU := uint64(unsafe.Sizeof(set) * 8)
+	for _, r := range strings.Split(list, ",") {
+		first, last := r, r
+		if i := strings.IndexByte(r, '-'); i != -1 {
+			first, last = r[:i], r[i+1:]
+		}
+		start, err := strconv.ParseUint(first, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CPU list %q: %w", list, err)
+		}
+		end, err := strconv.ParseUint(last, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CPU list %q: %w", list, err)
+		}
+		if start > end || end >= maxCPU {
+			return nil, fmt.Errorf("invalid CPU list %q: invalid range %q", list, r)
+		}
+		for cpu := start; cpu <= end; cpu++ {
+			set.Set(int(cpu))
+		}
+	}
+	return &set, nil
+}
+
+var ioprioClasses = map[specs.IOPriorityClass]int{
+	specs.IOPRIO_CLASS_RT:   1,
+	specs.IOPRIO_CLASS_BE:   2,
+	specs.IOPRIO_CLASS_IDLE: 3,
+}
+
+// ToIOPrio converts the I/O priority settings to the ioprio value used by
+// ioprio_set(2).
+func ToIOPrio(ioPriority *IOPriority) (int, error) {
+	class, ok := ioprioClasses[ioPriority.Class]
+	if !ok {
+		return 0, fmt.Errorf("invalid io priority class: %q", ioPriority.Class)
+	}
+	if ioPriority.Priority < 0 || ioPriority.Priority > 7 {
+		return 0, fmt.Errorf("invalid io priority %d: must be between 0 and 7", ioPriority.Priority)
+	}
+	// See IOPRIO_PRIO_VALUE in <linux/ioprio.h>.
+	return class<<13 | ioPriority.Priority, nil
+}
+
+// ToSchedAttr converts the scheduler settings to the sched_attr structure
+// used by sched_setattr(2).
+func ToSchedAttr(scheduler *Scheduler) (*system.SchedAttr, error) {
+	policy, ok := schedPolicies[scheduler.Policy]
+	if !ok {
+		return nil, fmt.Errorf("invalid scheduler policy: %q", scheduler.Policy)
+	}
+	var flags uint64
+	for _, f := range scheduler.Flags {
+		flag, ok := schedFlags[f]
+		if !ok {
+			return nil, fmt.Errorf("invalid scheduler flag: %q", f)
+		}
+		flags |= flag
+	}
+	if scheduler.UtilMin != 0 && flags&schedFlags[specs.SchedFlagUtilClampMin] == 0 {
+		return nil, fmt.Errorf("scheduler util_min requires the %s flag", specs.SchedFlagUtilClampMin)
+	}
+	if scheduler.UtilMax != 0 && flags&schedFlags[specs.SchedFlagUtilClampMax] == 0 {
+		return nil, fmt.Errorf("scheduler util_max requires the %s flag", specs.SchedFlagUtilClampMax)
+	}
+	return &system.SchedAttr{
+		Policy:   policy,
+		Flags:    flags,
+		Nice:     scheduler.Nice,
+		Priority: uint32(scheduler.Priority),
+		Runtime:  scheduler.Runtime,
+		Deadline: scheduler.Deadline,
+		Period:   scheduler.Period,
+		UtilMin:  scheduler.UtilMin,
+		UtilMax:  scheduler.UtilMax,
+	}, nil
+}
@@ -83,3 +83,47 @@ func TestHostRootGIDWithUSERNS(t *testing.T) {
 		t.Fatalf("expected gid 1000 with no USERNS but received %d", uid)
 	}
 }
+
+func TestToCPUSet(t *testing.T) {
+	testCases := []struct {
+		list  string
+		cpus  []int
+		isErr bool
+	}{
+		{list: ""},
+		{list: "0", cpus: []int{0}},
+		{list: "0-3,7", cpus: []int{0, 1, 2, 3, 7}},
+		{list: "2,4-5", cpus: []int{2, 4, 5}},
+		{list: "3-1", isErr: true},
+		{list: "0,", isErr: true},
+		{list: "a-b", isErr: true},
+		{list: "1048576", isErr: true},
+	}
+	for _, tc := range testCases {
+		set, err := ToCPUSet(tc.list)
+		if tc.isErr {
+			if err == nil {
+				t.Errorf("%q: expected error, got nil", tc.list)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", tc.list, err)
+			continue
+		}
+		if tc.list == "" {
+			if set != nil {
+				t.Errorf("%q: expected nil set", tc.list)
+			}
+			continue
+		}
+		if set.Count() != len(tc.cpus) {
+			t.Errorf("%q: expected %d CPUs, got %d", tc.list, len(tc.cpus), set.Count())
+		}
+		for _, cpu := range tc.cpus {
+			if !set.IsSet(cpu) {
+				t.Errorf("%q: expected CPU %d to be set", tc.list, cpu)
+			}
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package configs
+
+// Landlock is a Landlock ruleset restricting the filesystem accesses of
+// the container processes.
+type Landlock struct {
+	// HandledAccessFS is the list of filesystem access rights restricted
+	// by the ruleset, e.g. "read_file" or "write_file". Rights which are
+	// not listed here are not restricted.
+	HandledAccessFS []string `json:"handled_access_fs"`
+
+	// Rules allow some of the handled access rights for path hierarchies.
+	Rules []LandlockRule `json:"rules,omitempty"`
+
+	// ABI is the minimum Landlock ABI version required by the ruleset.
+	// The ruleset is enforced on a best-effort basis: access rights not
+	// known to the running kernel are dropped, as long as the kernel
+	// supports at least this ABI version. If ABI is 0 and the kernel does
+	// not support Landlock at all, the ruleset is not enforced.
+	ABI int `json:"abi,omitempty"`
+}
+
+// LandlockRule allows a set of access rights beneath the given paths.
+type LandlockRule struct {
+	// Paths are the files or directory hierarchies the rule applies to,
+	// as seen from inside the container.
+	Paths []string `json:"paths"`
+
+	// AllowedAccess is the list of access rights allowed for the paths.
+	// Rights which are not handled by the ruleset are ignored.
+	AllowedAccess []string `json:"allowed_access"`
+}
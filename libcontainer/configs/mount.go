@@ -35,8 +35,31 @@ type Mount struct {
 
 	// Extensions are additional flags that are specific to runc.
 	Extensions int `json:"extensions"`
+
+	// IDMapping is the ID mapping to apply to the mount, see mount_setattr(2).
+	// Only bind mounts can be ID-mapped.
+	IDMapping *MountIDMapping `json:"id_mapping,omitempty"`
+}
+
+// MountIDMapping describes the user namespace used to create an ID-mapped
+// mount (MOUNT_ATTR_IDMAP).
+type MountIDMapping struct {
+	// Recursive indicates if the mapping needs to be applied to all the
+	// submounts of the mount (AT_RECURSIVE).
+	Recursive bool `json:"recursive"`
+
+	// UIDMappings is the list of UID mappings of the user namespace.
+	UIDMappings []IDMap `json:"uid_mappings,omitempty"`
+
+	// GIDMappings is the list of GID mappings of the user namespace.
+	GIDMappings []IDMap `json:"gid_mappings,omitempty"`
 }
 
 func (m *Mount) IsBind() bool {
 	return m.Flags&unix.MS_BIND != 0
 }
+
+// IsIDMapped returns true if the mount has to be ID-mapped.
+func (m *Mount) IsIDMapped() bool {
+	return m.IDMapping != nil
+}
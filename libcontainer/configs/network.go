@@ -1,11 +1,13 @@
 package configs
 
+import "fmt"
+
 // Network defines configuration for a container's networking stack
 //
 // The network configuration can be omitted from a container causing the
 // container to be setup with the host's networking stack
 type Network struct {
-	// Type sets the networks type, commonly veth and loopback
+	// Type sets the networks type, one of loopback, veth, macvlan or ipvlan
 	Type string `json:"type"`
 
 	// Name of the network interface
@@ -43,11 +45,87 @@ type Network struct {
 	// container.
 	HostInterfaceName string `json:"host_interface_name"`
 
+	// HostInterfaceIndex is the index of the host side of the veth pair, set
+	// by runc when it is created, so that another interface which reused its
+	// name is not removed with the container.
+	HostInterfaceIndex int `json:"host_interface_index,omitempty"`
+
 	// HairpinMode specifies if hairpin NAT should be enabled on the virtual interface
 	// bridge port in the case of type veth
 	// Note: This is unsupported on some systems.
 	// Note: This does not apply to loopback interfaces.
 	HairpinMode bool `json:"hairpin_mode"`
+
+	// Parent is the name of the host network interface a macvlan or ipvlan
+	// interface is created on.
+	Parent string `json:"parent,omitempty"`
+
+	// Mode is the macvlan or ipvlan mode, one of NetworkModes.
+	Mode string `json:"mode,omitempty"`
+
+	// Egress limits the bandwidth of the traffic sent by the container on
+	// the interface. It is shaped by the Qdisc queueing discipline.
+	Egress *Bandwidth `json:"egress,omitempty"`
+
+	// Ingress limits the bandwidth of the traffic received by the container
+	// on the interface. It is policed, i.e. the traffic above the limit is
+	// dropped.
+	Ingress *Bandwidth `json:"ingress,omitempty"`
+
+	// Qdisc is the queueing discipline used to shape the egress traffic,
+	// either "tbf" (the default) or "htb".
+	Qdisc string `json:"qdisc,omitempty"`
+}
+
+// Modes of the macvlan and ipvlan networks.
+const (
+	MacvlanModeBridge  = "bridge"
+	MacvlanModePrivate = "private"
+	MacvlanModeVEPA    = "vepa"
+	IPVlanModeL2       = "l2"
+	IPVlanModeL3       = "l3"
+)
+
+// NetworkModes lists the valid modes of the network types which have them.
+// The first mode of each type is the default one, used if Mode is not set.
+var NetworkModes = map[string][]string{
+	"macvlan": {MacvlanModeBridge, MacvlanModePrivate, MacvlanModeVEPA},
+	"ipvlan":  {IPVlanModeL2, IPVlanModeL3},
+}
+
+// ModeOrDefault returns the mode of the network, or the default mode of its
+// type if Mode is not set. An error is returned if the mode is not valid for
+// the network type (see NetworkModes).
+func (n *Network) ModeOrDefault() (string, error) {
+	modes := NetworkModes[n.Type]
+	if len(modes) == 0 {
+		return "", fmt.Errorf("%s network does not have modes", n.Type)
+	}
+	if n.Mode == "" {
+		return modes[0], nil
+	}
+	for _, m := range modes {
+		if m == n.Mode {
+			return m, nil
+		}
+	}
+	return "", fmt.Errorf("unknown %s mode %q", n.Type, n.Mode)
+}
+
+// Bandwidth defines a bandwidth limit of a network interface.
+type Bandwidth struct {
+	// Rate is the rate limit, in bytes per second.
+	Rate uint64 `json:"rate"`
+
+	// Burst is the amount of data, in bytes, which can be sent or received
+	// at once above the rate. It should be at least the interface MTU. If
+	// not set, it defaults to the amount of data sent in a timer tick at
+	// the rate, plus 1600 bytes.
+	Burst uint32 `json:"burst,omitempty"`
+
+	// Latency is the maximum time, in microseconds, a packet can wait in
+	// the tbf queue (50ms if not set). It only applies to egress with tbf.
+	Latency uint32 `json:"latency,omitempty"`
 }
 
 // Route defines a routing table entry.
@@ -60,6 +138,8 @@ type Network struct {
 // IP family default for the route table.  For IPv4 for example, setting the
 // gateway to 1.2.3.4 and the interface to eth0 will set up a standard
 // destination of 0.0.0.0(or *) when viewed in the route table.
+// Either the gateway or the interface must be set; a route without a gateway
+// is a link scope route via the interface.
 type Route struct {
 	// Destination specifies the destination IP address and mask in the CIDR form.
 	Destination string `json:"destination"`
@@ -72,4 +152,8 @@ type Route struct {
 
 	// InterfaceName specifies the device to set this route up for, for example eth0.
 	InterfaceName string `json:"interface_name"`
+
+	// Metric specifies the route priority. Lower values are preferred;
+	// 0 is the kernel default.
+	Metric int `json:"metric,omitempty"`
 }
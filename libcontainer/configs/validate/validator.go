@@ -3,6 +3,8 @@ package validate
 import (
 	"errors"
 	"fmt"
+	"math"
+	"net"
 	"os"
 	"path/filepath"
 	"strings"
@@ -11,6 +13,8 @@ import (
 	"github.com/opencontainers/runc/libcontainer/cgroups"
 	"github.com/opencontainers/runc/libcontainer/configs"
 	"github.com/opencontainers/runc/libcontainer/intelrdt"
+	"github.com/opencontainers/runc/libcontainer/landlock"
+	"github.com/opencontainers/runtime-spec/specs-go"
 	selinux "github.com/opencontainers/selinux/go-selinux"
 	"github.com/sirupsen/logrus"
 	"golang.org/x/sys/unix"
@@ -29,6 +33,12 @@ func Validate(config *configs.Config) error {
 		sysctl,
 		intelrdtCheck,
 		rootlessEUIDCheck,
+		idmappedMounts,
+		landlockCheck,
+		scheduler,
+		ioPriority,
+		execCPUAffinity,
+		personality,
 	}
 	for _, c := range checks {
 		if err := c(config); err != nil {
@@ -72,6 +82,85 @@ func network(config *configs.Config) error {
 			return errors.New("unable to apply network settings without a private NET namespace")
 		}
 	}
+	for _, n := range config.Networks {
+		switch n.Type {
+		case "veth":
+			if n.Name == "" || n.HostInterfaceName == "" {
+				return errors.New("veth network requires both name and host interface name")
+			}
+		case "macvlan", "ipvlan":
+			if n.Name == "" || n.Parent == "" {
+				return fmt.Errorf("%s network requires both name and parent", n.Type)
+			}
+			if _, err := n.ModeOrDefault(); err != nil {
+				return err
+			}
+			if n.Type == "ipvlan" && n.MacAddress != "" {
+				return errors.New("ipvlan network can't have a MAC address")
+			}
+		}
+		for _, a := range []string{n.Address, n.IPv6Address} {
+			if a == "" {
+				continue
+			}
+			if _, _, err := net.ParseCIDR(a); err != nil {
+				return fmt.Errorf("invalid network address %q: %w", a, err)
+			}
+		}
+		if err := Bandwidth(n); err != nil {
+			return err
+		}
+	}
+	for _, r := range config.Routes {
+		if r.Gateway == "" && r.InterfaceName == "" {
+			return errors.New("route requires either a gateway or an interface name")
+		}
+		if r.Metric < 0 {
+			return fmt.Errorf("invalid route metric %d", r.Metric)
+		}
+	}
+	return nil
+}
+
+// Bandwidth checks the bandwidth limits of the network n.
+func Bandwidth(n *configs.Network) error {
+	if n.Egress == nil && n.Ingress == nil {
+		if n.Qdisc != "" {
+			return errors.New("network qdisc requires an egress limit")
+		}
+		return nil
+	}
+	if n.Type == "loopback" {
+		return errors.New("unable to limit the bandwidth of a loopback network")
+	}
+	if e := n.Egress; e != nil {
+		if e.Rate == 0 {
+			return errors.New("network egress limit requires a rate")
+		}
+		switch n.Qdisc {
+		case "", "tbf":
+		case "htb":
+			if e.Latency != 0 {
+				return errors.New("network egress latency is only supported with the tbf qdisc")
+			}
+		default:
+			return fmt.Errorf("unknown network qdisc %q", n.Qdisc)
+		}
+	} else if n.Qdisc != "" {
+		return errors.New("network qdisc requires an egress limit")
+	}
+	if i := n.Ingress; i != nil {
+		if i.Rate == 0 {
+			return errors.New("network ingress limit requires a rate")
+		}
+		// The policer rate is 32 bits wide.
+		if i.Rate > math.MaxUint32 {
+			return fmt.Errorf("network ingress rate %d is too high", i.Rate)
+		}
+		if i.Latency != 0 {
+			return errors.New("network ingress latency is not supported")
+		}
+	}
 	return nil
 }
 
@@ -112,6 +201,24 @@ func namespaces(config *configs.Config) error {
 		}
 	}
 
+	if config.Namespaces.Contains(configs.NEWTIME) {
+		if _, err := os.Stat("/proc/self/timens_offsets"); os.IsNotExist(err) {
+			return errors.New("time namespaces aren't enabled in the kernel")
+		}
+		if config.Namespaces.PathOf(configs.NEWTIME) != "" && config.TimeOffsets != nil {
+			return errors.New("time namespace offsets can't be set when joining an existing time namespace")
+		}
+	} else if config.TimeOffsets != nil {
+		return errors.New("time namespace offsets specified, but time namespace isn't enabled in the config")
+	}
+	for clock := range config.TimeOffsets {
+		switch clock {
+		case "monotonic", "boottime":
+		default:
+			return fmt.Errorf("invalid time namespace clock %q: only monotonic and boottime can be offset", clock)
+		}
+	}
+
 	return nil
 }
 
@@ -261,6 +368,169 @@ func cgroupsCheck(config *configs.Config) error {
 		}
 	}
 
+	return memoryV2Check(r)
+}
+
+// memoryV2Check validates the cgroup v2 only memory settings.
+func memoryV2Check(r *configs.Resources) error {
+	limits := []struct {
+		name  string
+		value *int64
+	}{
+		{"memory.high", r.MemoryHigh},
+		{"memory.min", r.MemoryMin},
+		{"memory.swap.high", r.MemorySwapHigh},
+		{"memory.zswap.max", r.MemoryZswapMax},
+	}
+	set := r.MemoryOOMGroup != nil
+	for _, l := range limits {
+		if l.value == nil {
+			continue
+		}
+		set = true
+		if *l.value < -1 {
+			return fmt.Errorf("invalid %s value %d: must be -1 (unlimited) or non-negative", l.name, *l.value)
+		}
+	}
+	if set && !cgroups.IsCgroup2UnifiedMode() {
+		return errors.New("invalid configuration: memory.high, memory.min, memory.oom.group, memory.swap.high and memory.zswap.max require cgroup v2")
+	}
+
+	return nil
+}
+
+// idmappedMounts validates the ID-mapped mounts, if any.
+func idmappedMounts(config *configs.Config) error {
+	for _, m := range config.Mounts {
+		if !m.IsIDMapped() {
+			continue
+		}
+		if config.RootlessEUID {
+			return fmt.Errorf("invalid mount %+v: ID-mapped mounts are not supported for rootless containers", m)
+		}
+		if !m.IsBind() {
+			return fmt.Errorf("invalid mount %+v: ID-mapped mounts are only supported for bind mounts", m)
+		}
+		if len(m.IDMapping.UIDMappings) == 0 || len(m.IDMapping.GIDMappings) == 0 {
+			return fmt.Errorf("invalid mount %+v: ID-mapped mounts need both UID and GID mappings", m)
+		}
+	}
+
+	return nil
+}
+
+func landlockCheck(config *configs.Config) error {
+	if config.Landlock == nil {
+		return nil
+	}
+	if err := landlock.Validate(config.Landlock); err != nil {
+		return err
+	}
+	// landlock_restrict_self(2) requires either no_new_privs or
+	// CAP_SYS_ADMIN (in the container user namespace).
+	if config.NoNewPrivileges {
+		return nil
+	}
+	if config.Capabilities != nil {
+		for _, c := range config.Capabilities.Effective {
+			if c == "CAP_SYS_ADMIN" {
+				return nil
+			}
+		}
+	}
+	return errors.New("landlock: enforcing a ruleset requires noNewPrivileges or CAP_SYS_ADMIN")
+}
+
+func scheduler(config *configs.Config) error {
+	if config.Scheduler == nil {
+		return nil
+	}
+	return Scheduler(config.Scheduler)
+}
+
+// Scheduler validates the scheduler settings, see sched_setattr(2).
+// It is also used to validate the settings of the processes executed
+// in an existing container.
+func Scheduler(s *configs.Scheduler) error {
+	if s.Policy == "" {
+		return errors.New("scheduler policy is required")
+	}
+	if _, err := configs.ToSchedAttr(s); err != nil {
+		return err
+	}
+	if s.Nice < -20 || s.Nice > 19 {
+		return fmt.Errorf("invalid scheduler nice value %d: must be between -20 and 19", s.Nice)
+	}
+	// The maximum clamp value is SCHED_CAPACITY_SCALE.
+	if s.UtilMin > 1024 || s.UtilMax > 1024 {
+		return fmt.Errorf("invalid scheduler utilization clamp values %d-%d: must be between 0 and 1024", s.UtilMin, s.UtilMax)
+	}
+	for _, f := range s.Flags {
+		if f == specs.SchedFlagUtilClampMax && s.UtilMin > s.UtilMax {
+			return fmt.Errorf("scheduler util_min %d is greater than util_max %d", s.UtilMin, s.UtilMax)
+		}
+	}
+	switch s.Policy {
+	case specs.SchedFIFO, specs.SchedRR:
+		if s.Priority < 1 || s.Priority > 99 {
+			return fmt.Errorf("invalid scheduler priority %d: must be between 1 and 99 for %s", s.Priority, s.Policy)
+		}
+	default:
+		if s.Priority != 0 {
+			return fmt.Errorf("scheduler priority can only be set for %s or %s", specs.SchedFIFO, specs.SchedRR)
+		}
+	}
+	if s.Policy != specs.SchedDeadline && (s.Runtime != 0 || s.Deadline != 0 || s.Period != 0) {
+		return fmt.Errorf("scheduler runtime, deadline and period can only be set for %s", specs.SchedDeadline)
+	}
+	return nil
+}
+
+func ioPriority(config *configs.Config) error {
+	if config.IOPriority == nil {
+		return nil
+	}
+	return IOPriority(config.IOPriority)
+}
+
+// IOPriority validates the I/O priority settings, see ioprio_set(2).
+// It is also used to validate the settings of the processes executed
+// in an existing container.
+func IOPriority(ioPriority *configs.IOPriority) error {
+	_, err := configs.ToIOPrio(ioPriority)
+	return err
+}
+
+func execCPUAffinity(config *configs.Config) error {
+	if config.ExecCPUAffinity == nil {
+		return nil
+	}
+	return CPUAffinity(config.ExecCPUAffinity)
+}
+
+// CPUAffinity validates the CPU lists of the CPU affinity settings.
+// It is also used to validate the settings of the processes executed
+// in an existing container.
+func CPUAffinity(aff *configs.CPUAffinity) error {
+	if _, err := configs.ToCPUSet(aff.Initial); err != nil {
+		return fmt.Errorf("invalid initial CPU affinity: %w", err)
+	}
+	if _, err := configs.ToCPUSet(aff.Final); err != nil {
+		return fmt.Errorf("invalid final CPU affinity: %w", err)
+	}
+	return nil
+}
+
+func personality(config *configs.Config) error {
+	p := config.Personality
+	if p == nil {
+		return nil
+	}
+	switch p.Domain {
+	case configs.PerLinux, configs.PerLinux32:
+	default:
+		return fmt.Errorf("invalid personality domain %#x: only LINUX and LINUX32 are supported", p.Domain)
+	}
 	return nil
 }
 
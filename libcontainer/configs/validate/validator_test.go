@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/opencontainers/runc/libcontainer/configs"
+	"github.com/opencontainers/runtime-spec/specs-go"
 	"golang.org/x/sys/unix"
 )
 
@@ -65,6 +66,102 @@ func TestValidateNetworkRoutesWithoutNETNamespace(t *testing.T) {
 	}
 }
 
+func TestValidateNetworks(t *testing.T) {
+	testCases := []struct {
+		network *configs.Network
+		isErr   bool
+	}{
+		{&configs.Network{Type: "veth", Name: "eth0", HostInterfaceName: "veth0"}, false},
+		{&configs.Network{Type: "veth", Name: "eth0", HostInterfaceName: "veth0", Address: "10.0.0.2/24", IPv6Address: "fd00::2/64"}, false},
+		{&configs.Network{Type: "veth", Name: "eth0"}, true},
+		{&configs.Network{Type: "veth", HostInterfaceName: "veth0"}, true},
+		{&configs.Network{Type: "veth", Name: "eth0", HostInterfaceName: "veth0", Address: "10.0.0.2"}, true},
+		{&configs.Network{Type: "macvlan", Name: "eth0", Parent: "eno1"}, false},
+		{&configs.Network{Type: "macvlan", Name: "eth0", Parent: "eno1", Mode: "vepa"}, false},
+		{&configs.Network{Type: "macvlan", Name: "eth0", Parent: "eno1", Mode: "l3"}, true},
+		{&configs.Network{Type: "macvlan", Name: "eth0"}, true},
+		{&configs.Network{Type: "ipvlan", Name: "eth0", Parent: "eno1", Mode: "l3"}, false},
+		{&configs.Network{Type: "ipvlan", Name: "eth0", Parent: "eno1", Mode: "vepa"}, true},
+		{&configs.Network{Type: "ipvlan", Name: "eth0", Parent: "eno1", MacAddress: "02:42:ac:11:00:02"}, true},
+	}
+	for _, tc := range testCases {
+		config := &configs.Config{
+			Rootfs: "/var",
+			Namespaces: configs.Namespaces(
+				[]configs.Namespace{
+					{Type: configs.NEWNET},
+				},
+			),
+			Networks: []*configs.Network{tc.network},
+		}
+		err := Validate(config)
+		if tc.isErr && err == nil {
+			t.Errorf("%+v: expected error, got nil", tc.network)
+		}
+		if !tc.isErr && err != nil {
+			t.Errorf("%+v: unexpected error: %v", tc.network, err)
+		}
+	}
+}
+
+func TestValidateBandwidth(t *testing.T) {
+	testCases := []struct {
+		network *configs.Network
+		isErr   bool
+	}{
+		{&configs.Network{Type: "veth"}, false},
+		{&configs.Network{Type: "veth", Egress: &configs.Bandwidth{Rate: 1 << 20, Burst: 1 << 16, Latency: 10000}}, false},
+		{&configs.Network{Type: "veth", Egress: &configs.Bandwidth{Rate: 1 << 20}, Qdisc: "htb"}, false},
+		{&configs.Network{Type: "macvlan", Ingress: &configs.Bandwidth{Rate: 1 << 20, Burst: 1 << 16}}, false},
+		{&configs.Network{Type: "loopback", Egress: &configs.Bandwidth{Rate: 1 << 20}}, true},
+		{&configs.Network{Type: "veth", Egress: &configs.Bandwidth{}}, true},
+		{&configs.Network{Type: "veth", Egress: &configs.Bandwidth{Rate: 1 << 20}, Qdisc: "cake"}, true},
+		{&configs.Network{Type: "veth", Egress: &configs.Bandwidth{Rate: 1 << 20, Latency: 1000}, Qdisc: "htb"}, true},
+		{&configs.Network{Type: "veth", Qdisc: "tbf"}, true},
+		{&configs.Network{Type: "veth", Ingress: &configs.Bandwidth{Rate: 1 << 40}}, true},
+		{&configs.Network{Type: "veth", Ingress: &configs.Bandwidth{Rate: 1 << 20, Latency: 1000}}, true},
+	}
+	for _, tc := range testCases {
+		err := Bandwidth(tc.network)
+		if tc.isErr && err == nil {
+			t.Errorf("%+v: expected error, got nil", tc.network)
+		}
+		if !tc.isErr && err != nil {
+			t.Errorf("%+v: unexpected error: %v", tc.network, err)
+		}
+	}
+}
+
+func TestValidateRoutes(t *testing.T) {
+	testCases := []struct {
+		route *configs.Route
+		isErr bool
+	}{
+		{&configs.Route{Gateway: "10.0.0.1"}, false},
+		{&configs.Route{Destination: "fd00:1::/64", InterfaceName: "eth0", Metric: 100}, false},
+		{&configs.Route{Destination: "10.1.0.0/16"}, true},
+		{&configs.Route{Gateway: "10.0.0.1", Metric: -1}, true},
+	}
+	for _, tc := range testCases {
+		config := &configs.Config{
+			Rootfs: "/var",
+			Namespaces: configs.Namespaces(
+				[]configs.Namespace{
+					{Type: configs.NEWNET},
+				},
+			),
+			Routes: []*configs.Route{tc.route},
+		}
+		err := Validate(config)
+		if tc.isErr && err == nil {
+			t.Errorf("%+v: expected error, got nil", tc.route)
+		}
+		if !tc.isErr && err != nil {
+			t.Errorf("%+v: unexpected error: %v", tc.route, err)
+		}
+	}
+}
+
 func TestValidateHostname(t *testing.T) {
 	config := &configs.Config{
 		Rootfs:   "/var",
@@ -173,6 +270,78 @@ func TestValidateUsernamespaceWithoutUserNS(t *testing.T) {
 	}
 }
 
+func TestValidateTimeNamespace(t *testing.T) {
+	if _, err := os.Stat("/proc/self/timens_offsets"); os.IsNotExist(err) {
+		t.Skip("Test requires timens.")
+	}
+	config := &configs.Config{
+		Rootfs: "/var",
+		Namespaces: configs.Namespaces(
+			[]configs.Namespace{
+				{Type: configs.NEWTIME},
+			},
+		),
+		TimeOffsets: map[string]specs.LinuxTimeOffset{
+			"boottime":  {Secs: 150, Nanosecs: 314159},
+			"monotonic": {Secs: 256, Nanosecs: 151413},
+		},
+	}
+
+	err := Validate(config)
+	if err != nil {
+		t.Errorf("expected error to not occur %+v", err)
+	}
+}
+
+func TestValidateTimeNamespaceInvalid(t *testing.T) {
+	if _, err := os.Stat("/proc/self/timens_offsets"); os.IsNotExist(err) {
+		t.Skip("Test requires timens.")
+	}
+	testCases := []struct {
+		name    string
+		path    string
+		offsets map[string]specs.LinuxTimeOffset
+	}{
+		{
+			name:    "unknown clock",
+			offsets: map[string]specs.LinuxTimeOffset{"realtime": {Secs: 1}},
+		},
+		{
+			name:    "offsets with path",
+			path:    "/proc/self/ns/time",
+			offsets: map[string]specs.LinuxTimeOffset{"boottime": {Secs: 1}},
+		},
+	}
+	for _, tc := range testCases {
+		config := &configs.Config{
+			Rootfs: "/var",
+			Namespaces: configs.Namespaces(
+				[]configs.Namespace{
+					{Type: configs.NEWTIME, Path: tc.path},
+				},
+			),
+			TimeOffsets: tc.offsets,
+		}
+		if err := Validate(config); err == nil {
+			t.Errorf("%s: expected error to occur but it was nil", tc.name)
+		}
+	}
+}
+
+func TestValidateTimeOffsetsWithoutTimeNS(t *testing.T) {
+	config := &configs.Config{
+		Rootfs: "/var",
+		TimeOffsets: map[string]specs.LinuxTimeOffset{
+			"boottime": {Secs: 150},
+		},
+	}
+
+	err := Validate(config)
+	if err == nil {
+		t.Error("Expected error to occur but it was nil")
+	}
+}
+
 // TestConvertSysctlVariableToDotsSeparator tests whether the sysctl variable
 // can be correctly converted to a dot as a separator.
 func TestConvertSysctlVariableToDotsSeparator(t *testing.T) {
@@ -359,3 +528,308 @@ func TestValidateMounts(t *testing.T) {
 		}
 	}
 }
+
+func TestValidateIDMappedMounts(t *testing.T) {
+	idmap := []configs.IDMap{{ContainerID: 0, HostID: 100000, Size: 65536}}
+	testCases := []struct {
+		name     string
+		isErr    bool
+		flags    int
+		rootless bool
+		mapping  *configs.MountIDMapping
+	}{
+		{
+			name:    "bind",
+			flags:   unix.MS_BIND,
+			mapping: &configs.MountIDMapping{UIDMappings: idmap, GIDMappings: idmap},
+		},
+		{
+			name:    "not a bind mount",
+			isErr:   true,
+			mapping: &configs.MountIDMapping{UIDMappings: idmap, GIDMappings: idmap},
+		},
+		{
+			name:    "missing gid mappings",
+			isErr:   true,
+			flags:   unix.MS_BIND,
+			mapping: &configs.MountIDMapping{UIDMappings: idmap},
+		},
+		{
+			name:     "rootless",
+			isErr:    true,
+			flags:    unix.MS_BIND,
+			rootless: true,
+			mapping:  &configs.MountIDMapping{UIDMappings: idmap, GIDMappings: idmap},
+		},
+	}
+
+	for _, tc := range testCases {
+		config := &configs.Config{
+			Rootfs:       "/var",
+			RootlessEUID: tc.rootless,
+			Mounts: []*configs.Mount{
+				{
+					Source:      "/abs/path",
+					Destination: "/abs/path",
+					Flags:       tc.flags,
+					IDMapping:   tc.mapping,
+				},
+			},
+		}
+
+		err := idmappedMounts(config)
+		if tc.isErr && err == nil {
+			t.Errorf("%s: expected error, got nil", tc.name)
+		}
+		if !tc.isErr && err != nil {
+			t.Errorf("%s: expected nil, got error %v", tc.name, err)
+		}
+	}
+}
+
+func TestValidateLandlock(t *testing.T) {
+	testCases := []struct {
+		name     string
+		isErr    bool
+		landlock *configs.Landlock
+		noNNP    bool
+		caps     *configs.Capabilities
+	}{
+		{
+			name: "valid",
+			landlock: &configs.Landlock{
+				HandledAccessFS: []string{"read_file", "write_file", "truncate"},
+				Rules: []configs.LandlockRule{
+					{Paths: []string{"/usr", "/etc"}, AllowedAccess: []string{"read_file"}},
+				},
+				ABI: 1,
+			},
+		},
+		{
+			name:     "CAP_SYS_ADMIN without noNewPrivileges",
+			landlock: &configs.Landlock{HandledAccessFS: []string{"read_file"}},
+			noNNP:    true,
+			caps:     &configs.Capabilities{Effective: []string{"CAP_SYS_ADMIN"}},
+		},
+		{
+			name:     "neither noNewPrivileges nor CAP_SYS_ADMIN",
+			isErr:    true,
+			landlock: &configs.Landlock{HandledAccessFS: []string{"read_file"}},
+			noNNP:    true,
+			caps:     &configs.Capabilities{Bounding: []string{"CAP_SYS_ADMIN"}},
+		},
+		{
+			name:     "no handled access rights",
+			isErr:    true,
+			landlock: &configs.Landlock{},
+		},
+		{
+			name:  "unknown access right",
+			isErr: true,
+			landlock: &configs.Landlock{
+				HandledAccessFS: []string{"read_file", "chmod"},
+			},
+		},
+		{
+			name:  "rule without paths",
+			isErr: true,
+			landlock: &configs.Landlock{
+				HandledAccessFS: []string{"read_file"},
+				Rules:           []configs.LandlockRule{{AllowedAccess: []string{"read_file"}}},
+			},
+		},
+		{
+			name:  "unknown ABI version",
+			isErr: true,
+			landlock: &configs.Landlock{
+				HandledAccessFS: []string{"read_file"},
+				ABI:             100,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		config := &configs.Config{
+			Rootfs:          "/var",
+			Landlock:        tc.landlock,
+			NoNewPrivileges: !tc.noNNP,
+			Capabilities:    tc.caps,
+		}
+
+		err := landlockCheck(config)
+		if tc.isErr && err == nil {
+			t.Errorf("%s: expected error, got nil", tc.name)
+		}
+		if !tc.isErr && err != nil {
+			t.Errorf("%s: expected nil, got error %v", tc.name, err)
+		}
+	}
+}
+
+func TestValidateScheduler(t *testing.T) {
+	testCases := []struct {
+		name      string
+		isErr     bool
+		scheduler *configs.Scheduler
+	}{
+		{
+			name:      "batch",
+			scheduler: &configs.Scheduler{Policy: specs.SchedBatch, Nice: 10},
+		},
+		{
+			name:      "fifo",
+			scheduler: &configs.Scheduler{Policy: specs.SchedFIFO, Priority: 50},
+		},
+		{
+			name:      "deadline",
+			scheduler: &configs.Scheduler{Policy: specs.SchedDeadline, Runtime: 10000, Deadline: 20000, Period: 30000},
+		},
+		{
+			name:      "util clamp",
+			scheduler: &configs.Scheduler{Policy: specs.SchedOther, Flags: []specs.LinuxSchedulerFlag{specs.SchedFlagUtilClampMin, specs.SchedFlagUtilClampMax}, UtilMin: 256, UtilMax: 512},
+		},
+		{
+			name:      "util max of zero",
+			scheduler: &configs.Scheduler{Policy: specs.SchedOther, Flags: []specs.LinuxSchedulerFlag{specs.SchedFlagUtilClampMax}},
+		},
+		{
+			name:      "no policy",
+			isErr:     true,
+			scheduler: &configs.Scheduler{Nice: 10},
+		},
+		{
+			name:      "unknown policy",
+			isErr:     true,
+			scheduler: &configs.Scheduler{Policy: "SCHED_FOO"},
+		},
+		{
+			name:      "unknown flag",
+			isErr:     true,
+			scheduler: &configs.Scheduler{Policy: specs.SchedOther, Flags: []specs.LinuxSchedulerFlag{"SCHED_FLAG_FOO"}},
+		},
+		{
+			name:      "util clamp without flag",
+			isErr:     true,
+			scheduler: &configs.Scheduler{Policy: specs.SchedOther, UtilMin: 512},
+		},
+		{
+			name:      "util clamp out of range",
+			isErr:     true,
+			scheduler: &configs.Scheduler{Policy: specs.SchedOther, Flags: []specs.LinuxSchedulerFlag{specs.SchedFlagUtilClampMax}, UtilMax: 1025},
+		},
+		{
+			name:      "util min greater than util max",
+			isErr:     true,
+			scheduler: &configs.Scheduler{Policy: specs.SchedOther, Flags: []specs.LinuxSchedulerFlag{specs.SchedFlagUtilClampMin, specs.SchedFlagUtilClampMax}, UtilMin: 512, UtilMax: 256},
+		},
+		{
+			name:      "nice out of range",
+			isErr:     true,
+			scheduler: &configs.Scheduler{Policy: specs.SchedOther, Nice: 20},
+		},
+		{
+			name:      "priority without realtime policy",
+			isErr:     true,
+			scheduler: &configs.Scheduler{Policy: specs.SchedOther, Priority: 10},
+		},
+		{
+			name:      "realtime policy without priority",
+			isErr:     true,
+			scheduler: &configs.Scheduler{Policy: specs.SchedRR},
+		},
+		{
+			name:      "deadline attributes without deadline policy",
+			isErr:     true,
+			scheduler: &configs.Scheduler{Policy: specs.SchedBatch, Runtime: 10000},
+		},
+	}
+
+	for _, tc := range testCases {
+		config := &configs.Config{
+			Rootfs:    "/var",
+			Scheduler: tc.scheduler,
+		}
+
+		err := scheduler(config)
+		if tc.isErr && err == nil {
+			t.Errorf("%s: expected error, got nil", tc.name)
+		}
+		if !tc.isErr && err != nil {
+			t.Errorf("%s: expected nil, got error %v", tc.name, err)
+		}
+	}
+}
+
+func TestValidateIOPriority(t *testing.T) {
+	testCases := []struct {
+		name       string
+		isErr      bool
+		ioPriority *configs.IOPriority
+	}{
+		{
+			name:       "best effort",
+			ioPriority: &configs.IOPriority{Class: specs.IOPRIO_CLASS_BE, Priority: 7},
+		},
+		{
+			name:       "idle",
+			ioPriority: &configs.IOPriority{Class: specs.IOPRIO_CLASS_IDLE},
+		},
+		{
+			name:       "unknown class",
+			isErr:      true,
+			ioPriority: &configs.IOPriority{Class: "IOPRIO_CLASS_FOO"},
+		},
+		{
+			name:       "negative priority",
+			isErr:      true,
+			ioPriority: &configs.IOPriority{Class: specs.IOPRIO_CLASS_RT, Priority: -1},
+		},
+		{
+			name:       "priority out of range",
+			isErr:      true,
+			ioPriority: &configs.IOPriority{Class: specs.IOPRIO_CLASS_BE, Priority: 8},
+		},
+	}
+
+	for _, tc := range testCases {
+		config := &configs.Config{
+			Rootfs:     "/var",
+			IOPriority: tc.ioPriority,
+		}
+
+		err := ioPriority(config)
+		if tc.isErr && err == nil {
+			t.Errorf("%s: expected error, got nil", tc.name)
+		}
+		if !tc.isErr && err != nil {
+			t.Errorf("%s: expected nil, got error %v", tc.name, err)
+		}
+	}
+}
+
+func TestValidatePersonality(t *testing.T) {
+	testCases := []struct {
+		name        string
+		isErr       bool
+		personality *configs.LinuxPersonality
+	}{
+		{name: "linux", personality: &configs.LinuxPersonality{Domain: configs.PerLinux}},
+		{name: "linux32", personality: &configs.LinuxPersonality{Domain: configs.PerLinux32}},
+		{name: "unsupported domain", isErr: true, personality: &configs.LinuxPersonality{Domain: 0x0010}},
+	}
+
+	for _, tc := range testCases {
+		config := &configs.Config{
+			Rootfs:      "/var",
+			Personality: tc.personality,
+		}
+
+		err := personality(config)
+		if tc.isErr && err == nil {
+			t.Errorf("%s: expected error, got nil", tc.name)
+		}
+		if !tc.isErr && err != nil {
+			t.Errorf("%s: expected nil, got error %v", tc.name, err)
+		}
+	}
+}
@@ -90,6 +90,12 @@ type BaseContainer interface {
 	// Returns statistics for the container.
 	Stats() (*Stats, error)
 
+	// FillStats fills stats with the statistics of the container selected
+	// by mask. The stats can be reused between calls, to avoid allocating
+	// them every time, in which case their previous maps and slices are
+	// overwritten.
+	FillStats(stats *Stats, mask StatsMask) error
+
 	// Set resources of container as configured
 	//
 	// We can use this to change resources when containers are running.
@@ -2,6 +2,7 @@ package libcontainer
 
 import (
 	"bytes"
+	"encoding/binary"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -49,6 +50,7 @@ type linuxContainer struct {
 	state                containerState
 	created              time.Time
 	fifo                 *os.File
+	netStatsWarn         sync.Once
 }
 
 // State represents a running container's state
@@ -112,6 +114,16 @@ type Container interface {
 
 	// NotifyMemoryPressure returns a read-only channel signaling when the container reaches a given pressure level
 	NotifyMemoryPressure(level PressureLevel) (<-chan struct{}, error)
+
+	// NotifyMemoryPressureTrigger returns a read-only channel signaling when the given PSI trigger
+	// fires for the container's memory. It is only supported on cgroup v2.
+	NotifyMemoryPressureTrigger(trigger PSITrigger) (<-chan struct{}, error)
+
+	// Wait blocks until the container's init process exits, or until the
+	// timeout (if non-zero) expires, and returns its exit status. The exit
+	// status is nil if it can not be determined (e.g. if init has already
+	// been reaped by its parent which is not the caller).
+	Wait(timeout time.Duration) (*ExitStatus, error)
 }
 
 // ID returns the container's unique ID
@@ -161,29 +173,62 @@ func (c *linuxContainer) Processes() ([]int, error) {
 }
 
 func (c *linuxContainer) Stats() (*Stats, error) {
-	var (
-		err   error
-		stats = &Stats{}
-	)
-	if stats.CgroupStats, err = c.cgroupManager.GetStats(); err != nil {
-		return stats, fmt.Errorf("unable to get container cgroup stats: %w", err)
+	stats := &Stats{}
+	return stats, c.FillStats(stats, StatsAll)
+}
+
+func (c *linuxContainer) FillStats(stats *Stats, mask StatsMask) error {
+	var err error
+	if stats.CgroupStats == nil {
+		stats.CgroupStats = &cgroups.Stats{}
 	}
-	if c.intelRdtManager != nil {
+	if err := c.cgroupManager.FillStats(stats.CgroupStats, mask.cgroupStats()); err != nil {
+		return fmt.Errorf("unable to get container cgroup stats: %w", err)
+	}
+	stats.IntelRdtStats = nil
+	if c.intelRdtManager != nil && mask&StatsIntelRdt != 0 {
 		if stats.IntelRdtStats, err = c.intelRdtManager.GetStats(); err != nil {
-			return stats, fmt.Errorf("unable to get container Intel RDT stats: %w", err)
+			return fmt.Errorf("unable to get container Intel RDT stats: %w", err)
+		}
+	}
+	stats.Interfaces = stats.Interfaces[:0]
+	if mask&StatsNetwork == 0 {
+		return nil
+	}
+	if c.config.Namespaces.Contains(configs.NEWNET) && c.initProcess != nil {
+		// This includes the interfaces not set up by runc, e.g. by CNI.
+		if stats.Interfaces, err = getNetnsInterfaceStats(c.initProcess.pid()); err == nil {
+			for _, istats := range stats.Interfaces {
+				for _, iface := range c.config.Networks {
+					if iface.Type == "veth" && iface.Name == istats.Name {
+						istats.HostName = iface.HostInterfaceName
+					}
+				}
+			}
+			return nil
 		}
+		// For example, a rootless container network namespace can't be
+		// entered without entering its user namespace. The host side stats
+		// below don't include the qdisc drops, as the bandwidth limits are
+		// set on the container side. Only warn once, as the stats may be
+		// collected at regular intervals.
+		c.netStatsWarn.Do(func() {
+			logrus.Warnf("unable to get network stats from the container network namespace, using the host side of the veth interfaces: %v", err)
+		})
 	}
 	for _, iface := range c.config.Networks {
 		switch iface.Type {
 		case "veth":
 			istats, err := getNetworkInterfaceStats(iface.HostInterfaceName)
 			if err != nil {
-				return stats, fmt.Errorf("unable to get network stats for interface %q: %w", iface.HostInterfaceName, err)
+				return fmt.Errorf("unable to get network stats for interface %q: %w", iface.HostInterfaceName, err)
 			}
+			istats.Name = iface.Name
+			istats.HostName = iface.HostInterfaceName
 			stats.Interfaces = append(stats.Interfaces, istats)
 		}
 	}
-	return stats, nil
+	return nil
 }
 
 func (c *linuxContainer) Set(config configs.Config) error {
@@ -215,6 +260,15 @@ func (c *linuxContainer) Set(config configs.Config) error {
 			return err
 		}
 	}
+	if c.initProcess != nil {
+		if err := updateShaping(c.initProcess.pid(), c.config.Networks, config.Networks); err != nil {
+			// Set configs back
+			if err2 := updateShaping(c.initProcess.pid(), config.Networks, c.config.Networks); err2 != nil {
+				logrus.Warnf("Setting back network bandwidth limits failed due to error: %v, your state.json and actual configs might be inconsistent.", err2)
+			}
+			return fmt.Errorf("unable to update network bandwidth limits: %w", err)
+		}
+	}
 	// After config setting succeed, update config and states
 	c.config = &config
 	_, err = c.updateState(nil)
@@ -533,9 +587,10 @@ func (c *linuxContainer) shouldSendMountSources() bool {
 		return false
 	}
 
-	// We need to send sources if there are bind-mounts.
+	// We need to send sources if there are bind-mounts. ID-mapped mounts
+	// are opened by the parent instead, see idmappedMountFiles.
 	for _, m := range c.config.Mounts {
-		if m.IsBind() {
+		if m.IsBind() && !m.IsIDMapped() {
 			return true
 		}
 	}
@@ -552,7 +607,8 @@ func (c *linuxContainer) newInitProcess(p *Process, cmd *exec.Cmd, messageSockPa
 		}
 	}
 	_, sharePidns := nsMaps[configs.NEWPID]
-	data, err := c.bootstrapData(c.config.Namespaces.CloneFlags(), nsMaps, initStandard)
+	config := c.newInitConfig(p)
+	data, err := c.bootstrapData(c.config.Namespaces.CloneFlags(), nsMaps, initStandard, config.CPUAffinity)
 	if err != nil {
 		return nil, err
 	}
@@ -562,8 +618,9 @@ func (c *linuxContainer) newInitProcess(p *Process, cmd *exec.Cmd, messageSockPa
 		// prepareRootfs()). This slice MUST have the same size as c.config.Mounts.
 		mountFds := make([]int, len(c.config.Mounts))
 		for i, m := range c.config.Mounts {
-			if !m.IsBind() {
-				// Non bind-mounts do not use an fd.
+			if !m.IsBind() || m.IsIDMapped() {
+				// Non bind-mounts do not use an fd, and ID-mapped
+				// mounts use one from idmappedMountFiles.
 				mountFds[i] = -1
 				continue
 			}
@@ -586,13 +643,19 @@ func (c *linuxContainer) newInitProcess(p *Process, cmd *exec.Cmd, messageSockPa
 		)
 	}
 
+	idmapFiles, err := c.idmappedMountFiles(cmd)
+	if err != nil {
+		return nil, err
+	}
+
 	init := &initProcess{
 		cmd:             cmd,
+		idmapFiles:      idmapFiles,
 		messageSockPair: messageSockPair,
 		logFilePair:     logFilePair,
 		manager:         c.cgroupManager,
 		intelRdtManager: c.intelRdtManager,
-		config:          c.newInitConfig(p),
+		config:          config,
 		container:       c,
 		process:         p,
 		bootstrapData:   data,
@@ -602,6 +665,48 @@ func (c *linuxContainer) newInitProcess(p *Process, cmd *exec.Cmd, messageSockPa
 	return init, nil
 }
 
+// idmappedMountFiles creates a detached ID-mapped mount for every mount that
+// requires it, and passes them to the child via cmd.ExtraFiles. The mounts are
+// created by the parent because, unlike the child, it has the privileges to
+// create a user namespace with arbitrary mappings. The caller must close the
+// returned files once the child has been started.
+func (c *linuxContainer) idmappedMountFiles(cmd *exec.Cmd) (_ []*os.File, retErr error) {
+	var files []*os.File
+	defer func() {
+		if retErr != nil {
+			for _, f := range files {
+				_ = f.Close()
+			}
+		}
+	}()
+
+	idmapFds := make([]int, len(c.config.Mounts))
+	for i, m := range c.config.Mounts {
+		if !m.IsIDMapped() {
+			idmapFds[i] = -1
+			continue
+		}
+		f, err := createIDMappedMount(m)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+		cmd.ExtraFiles = append(cmd.ExtraFiles, f)
+		idmapFds[i] = stdioFdCount + len(cmd.ExtraFiles) - 1
+	}
+	if len(files) == 0 {
+		return nil, nil
+	}
+
+	idmapFdsJSON, err := json.Marshal(idmapFds)
+	if err != nil {
+		return nil, fmt.Errorf("error creating _LIBCONTAINER_IDMAP_FDS: %w", err)
+	}
+	cmd.Env = append(cmd.Env, "_LIBCONTAINER_IDMAP_FDS="+string(idmapFdsJSON))
+
+	return files, nil
+}
+
 func (c *linuxContainer) newSetnsProcess(p *Process, cmd *exec.Cmd, messageSockPair, logFilePair filePair) (*setnsProcess, error) {
 	cmd.Env = append(cmd.Env, "_LIBCONTAINER_INITTYPE="+string(initSetns))
 	state, err := c.currentState()
@@ -610,7 +715,8 @@ func (c *linuxContainer) newSetnsProcess(p *Process, cmd *exec.Cmd, messageSockP
 	}
 	// for setns process, we don't have to set cloneflags as the process namespaces
 	// will only be set via setns syscall
-	data, err := c.bootstrapData(0, state.NamespacePaths, initSetns)
+	config := c.newInitConfig(p)
+	data, err := c.bootstrapData(0, state.NamespacePaths, initSetns, config.CPUAffinity)
 	if err != nil {
 		return nil, err
 	}
@@ -622,7 +728,7 @@ func (c *linuxContainer) newSetnsProcess(p *Process, cmd *exec.Cmd, messageSockP
 		messageSockPair: messageSockPair,
 		logFilePair:     logFilePair,
 		manager:         c.cgroupManager,
-		config:          c.newInitConfig(p),
+		config:          config,
 		process:         p,
 		bootstrapData:   data,
 		initProcessPid:  state.InitProcessPid,
@@ -667,7 +773,10 @@ func (c *linuxContainer) newInitConfig(process *Process) *initConfig {
 		RootlessCgroups:  c.config.RootlessCgroups,
 		AppArmorProfile:  c.config.AppArmorProfile,
 		ProcessLabel:     c.config.ProcessLabel,
+		Landlock:         c.config.Landlock,
 		Rlimits:          c.config.Rlimits,
+		Scheduler:        c.config.Scheduler,
+		IOPriority:       c.config.IOPriority,
 		CreateConsole:    process.ConsoleSocket != nil,
 		ConsoleWidth:     process.ConsoleWidth,
 		ConsoleHeight:    process.ConsoleHeight,
@@ -681,9 +790,23 @@ func (c *linuxContainer) newInitConfig(process *Process) *initConfig {
 	if process.Label != "" {
 		cfg.ProcessLabel = process.Label
 	}
+	if process.Landlock != nil {
+		cfg.Landlock = process.Landlock
+	}
 	if len(process.Rlimits) > 0 {
 		cfg.Rlimits = process.Rlimits
 	}
+	if process.Scheduler != nil {
+		cfg.Scheduler = process.Scheduler
+	}
+	if process.IOPriority != nil {
+		cfg.IOPriority = process.IOPriority
+	}
+	if process.CPUAffinity != nil {
+		cfg.CPUAffinity = process.CPUAffinity
+	} else if !process.Init {
+		cfg.CPUAffinity = c.config.ExecCPUAffinity
+	}
 	if cgroups.IsCgroup2UnifiedMode() {
 		cfg.Cgroup2Path = c.cgroupManager.Path("")
 	}
@@ -751,7 +874,22 @@ func (c *linuxContainer) NotifyMemoryPressure(level PressureLevel) (<-chan struc
 	if c.config.RootlessCgroups {
 		logrus.Warn("getting memory pressure notifications may fail if you don't have the full access to cgroups")
 	}
-	return notifyMemoryPressure(c.cgroupManager.Path("memory"), level)
+	path := c.cgroupManager.Path("memory")
+	if cgroups.IsCgroup2UnifiedMode() {
+		return notifyMemoryPressureV2(path, level)
+	}
+	return notifyMemoryPressure(path, level)
+}
+
+func (c *linuxContainer) NotifyMemoryPressureTrigger(trigger PSITrigger) (<-chan struct{}, error) {
+	if !cgroups.IsCgroup2UnifiedMode() {
+		return nil, errors.New("memory pressure triggers require cgroup v2")
+	}
+	// XXX(cyphar): This requires cgroups.
+	if c.config.RootlessCgroups {
+		logrus.Warn("getting memory pressure notifications may fail if you don't have the full access to cgroups")
+	}
+	return registerPSITrigger(c.cgroupManager.Path("memory"), "memory.pressure", trigger)
 }
 
 var criuFeatures *criurpc.CriuFeatures
@@ -1959,6 +2097,9 @@ func (c *linuxContainer) runType() Status {
 	if c.initProcess == nil {
 		return Stopped
 	}
+	if pidfdExited(c.initProcess.pidfd()) {
+		return Stopped
+	}
 	pid := c.initProcess.pid()
 	stat, err := system.Stat(pid)
 	if err != nil {
@@ -2106,7 +2247,7 @@ type netlinkError struct{ error }
 // such as one that uses nsenter package to bootstrap the container's
 // init process correctly, i.e. with correct namespaces, uid/gid
 // mapping etc.
-func (c *linuxContainer) bootstrapData(cloneFlags uintptr, nsMaps map[configs.NamespaceType]string, it initType) (_ io.Reader, Err error) {
+func (c *linuxContainer) bootstrapData(cloneFlags uintptr, nsMaps map[configs.NamespaceType]string, it initType, cpuAffinity *configs.CPUAffinity) (_ io.Reader, Err error) {
 	// create the netlink message
 	r := nl.NewNetlinkRequest(int(InitMsg), 0)
 
@@ -2202,6 +2343,40 @@ func (c *linuxContainer) bootstrapData(cloneFlags uintptr, nsMaps map[configs.Na
 		})
 	}
 
+	// write time namespace offsets, these can only be set for a new namespace
+	if it == initStandard && c.config.TimeOffsets != nil {
+		var offsets bytes.Buffer
+		for clock, offset := range c.config.TimeOffsets {
+			fmt.Fprintf(&offsets, "%s %d %d\n", clock, offset.Secs, offset.Nanosecs)
+		}
+		r.AddData(&Bytemsg{
+			Type:  TimeOffsetsAttr,
+			Value: offsets.Bytes(),
+		})
+	}
+
+	// write the initial CPU affinity, set by nsexec as soon as it starts.
+	// It is not inherited from the thread starting the process, as that
+	// thread would have to be dedicated to it and outlive it, the parent
+	// death signal being sent when the thread exits, and as the affinity
+	// is reset when the process is started directly in a cgroup with a
+	// cpuset (CLONE_INTO_CGROUP).
+	if cpuAffinity != nil && cpuAffinity.Initial != "" {
+		initial, err := configs.ToCPUSet(cpuAffinity.Initial)
+		if err != nil {
+			return nil, err
+		}
+		// unix.CPUSet has the same layout as cpu_set_t.
+		var mask bytes.Buffer
+		if err := binary.Write(&mask, nl.NativeEndian(), initial); err != nil {
+			return nil, err
+		}
+		r.AddData(&Bytemsg{
+			Type:  CPUAffinityAttr,
+			Value: mask.Bytes(),
+		})
+	}
+
 	// write rootless
 	r.AddData(&Boolmsg{
 		Type:  RootlessEUIDAttr,
@@ -2212,7 +2387,7 @@ func (c *linuxContainer) bootstrapData(cloneFlags uintptr, nsMaps map[configs.Na
 	if it == initStandard && c.shouldSendMountSources() {
 		var mounts []byte
 		for _, m := range c.config.Mounts {
-			if m.IsBind() {
+			if m.IsBind() && !m.IsIDMapped() {
 				if strings.IndexByte(m.Source, 0) >= 0 {
 					return nil, fmt.Errorf("mount source string contains null byte: %q", m.Source)
 				}
@@ -28,6 +28,10 @@ func (m *mockCgroupManager) GetStats() (*cgroups.Stats, error) {
 	return nil, nil
 }
 
+func (m *mockCgroupManager) FillStats(_ *cgroups.Stats, _ cgroups.StatsMask) error {
+	return nil
+}
+
 func (m *mockCgroupManager) Apply(pid int) error {
 	return nil
 }
@@ -70,8 +74,9 @@ func (m *mockCgroupManager) GetFreezerState() (configs.FreezerState, error) {
 }
 
 type mockProcess struct {
-	_pid    int
-	started uint64
+	_pid      int
+	started   uint64
+	pidfdFile *os.File
 }
 
 func (m *mockProcess) terminate() error {
@@ -105,6 +110,10 @@ func (m *mockProcess) externalDescriptors() []string {
 func (m *mockProcess) setExternalDescriptors(newFds []string) {
 }
 
+func (m *mockProcess) pidfd() *os.File {
+	return m.pidfdFile
+}
+
 func (m *mockProcess) forwardChildLogs() chan error {
 	return nil
 }
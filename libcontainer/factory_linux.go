@@ -297,17 +297,29 @@ func (l *LinuxFactory) validateID(id string) error {
 	return nil
 }
 
-func parseMountFds() ([]int, error) {
-	fdsJSON := os.Getenv("_LIBCONTAINER_MOUNT_FDS")
+func parseMountFds() (mountFds, error) {
+	sourceFds, err := parseFdsEnv("_LIBCONTAINER_MOUNT_FDS")
+	if err != nil {
+		return mountFds{}, err
+	}
+	idmapFds, err := parseFdsEnv("_LIBCONTAINER_IDMAP_FDS")
+	if err != nil {
+		return mountFds{}, err
+	}
+	return mountFds{sourceFds: sourceFds, idmapFds: idmapFds}, nil
+}
+
+func parseFdsEnv(name string) ([]int, error) {
+	fdsJSON := os.Getenv(name)
 	if fdsJSON == "" {
 		// Always return the nil slice if no fd is present.
 		return nil, nil
 	}
 
-	var mountFds []int
-	if err := json.Unmarshal([]byte(fdsJSON), &mountFds); err != nil {
-		return nil, fmt.Errorf("Error unmarshalling _LIBCONTAINER_MOUNT_FDS: %w", err)
+	var fds []int
+	if err := json.Unmarshal([]byte(fdsJSON), &fds); err != nil {
+		return nil, fmt.Errorf("Error unmarshalling %s: %w", name, err)
 	}
 
-	return mountFds, nil
+	return fds, nil
 }
@@ -8,6 +8,7 @@ import (
 	"io"
 	"net"
 	"os"
+	"strconv"
 	"strings"
 	"unsafe"
 
@@ -42,7 +43,8 @@ type network struct {
 	configs.Network
 
 	// TempVethPeerName is a unique temporary veth peer name that was placed into
-	// the container's namespace.
+	// the container's namespace. It is also used for the temporary name of
+	// macvlan and ipvlan links.
 	TempVethPeerName string `json:"temp_veth_peer_name"`
 }
 
@@ -54,6 +56,7 @@ type initConfig struct {
 	Capabilities     *configs.Capabilities `json:"capabilities"`
 	ProcessLabel     string                `json:"process_label"`
 	AppArmorProfile  string                `json:"apparmor_profile"`
+	Landlock         *configs.Landlock     `json:"landlock,omitempty"`
 	NoNewPrivileges  bool                  `json:"no_new_privileges"`
 	User             string                `json:"user"`
 	AdditionalGroups []string              `json:"additional_groups"`
@@ -62,6 +65,9 @@ type initConfig struct {
 	PassedFilesCount int                   `json:"passed_files_count"`
 	ContainerID      string                `json:"containerid"`
 	Rlimits          []configs.Rlimit      `json:"rlimits"`
+	Scheduler        *configs.Scheduler    `json:"scheduler,omitempty"`
+	IOPriority       *configs.IOPriority   `json:"io_priority,omitempty"`
+	CPUAffinity      *configs.CPUAffinity  `json:"cpu_affinity,omitempty"`
 	CreateConsole    bool                  `json:"create_console"`
 	ConsoleWidth     uint16                `json:"console_width"`
 	ConsoleHeight    uint16                `json:"console_height"`
@@ -75,7 +81,18 @@ type initer interface {
 	Init() error
 }
 
-func newContainerInit(t initType, pipe *os.File, consoleSocket *os.File, fifoFd, logFd int, mountFds []int) (initer, error) {
+// mountFds holds the file descriptors prepared by the parent for the mounts
+// of the container. Both slices, if not nil, have the same length as
+// config.Mounts, and contain -1 for mounts that don't use an fd.
+type mountFds struct {
+	// sourceFds are the (O_PATH) mount sources opened outside of the
+	// container user namespace.
+	sourceFds []int
+	// idmapFds are the detached ID-mapped mounts created by the parent.
+	idmapFds []int
+}
+
+func newContainerInit(t initType, pipe *os.File, consoleSocket *os.File, fifoFd, logFd int, mountFds mountFds) (initer, error) {
 	var config *initConfig
 	if err := json.NewDecoder(pipe).Decode(&config); err != nil {
 		return nil, err
@@ -86,7 +103,7 @@ func newContainerInit(t initType, pipe *os.File, consoleSocket *os.File, fifoFd,
 	switch t {
 	case initSetns:
 		// mountFds must be nil in this case. We don't mount while doing runc exec.
-		if mountFds != nil {
+		if mountFds.sourceFds != nil || mountFds.idmapFds != nil {
 			return nil, errors.New("mountFds must be nil; can't mount from exec")
 		}
 
@@ -276,6 +293,36 @@ func syncParentHooks(pipe io.ReadWriter) error {
 	return readSync(pipe, procResume)
 }
 
+// seccompConfig returns the seccomp configuration to load for config. As
+// the Landlock ruleset is enforced after the seccomp filter is loaded,
+// landlock_restrict_self(2) is always allowed if a ruleset is set. This
+// does not weaken the filter, since the syscall can only add restrictions.
+func seccompConfig(config *initConfig) *configs.Seccomp {
+	sc := config.Config.Seccomp
+	if sc == nil || config.Landlock == nil {
+		return sc
+	}
+	syscalls := make([]*configs.Syscall, 0, len(sc.Syscalls)+1)
+	for _, call := range sc.Syscalls {
+		if call != nil && call.Name == "landlock_restrict_self" {
+			if call.Action != configs.Allow {
+				logrus.Warn("seccomp: allowing landlock_restrict_self(2) despite the profile, to enforce the Landlock ruleset")
+			}
+			continue
+		}
+		syscalls = append(syscalls, call)
+	}
+	if sc.DefaultAction != configs.Allow {
+		logrus.Debug("seccomp: allowing landlock_restrict_self(2) to enforce the Landlock ruleset")
+	}
+	c := *sc
+	c.Syscalls = append(syscalls, &configs.Syscall{
+		Name:   "landlock_restrict_self",
+		Action: configs.Allow,
+	})
+	return &c
+}
+
 // syncParentSeccomp sends to the given pipe a JSON payload which
 // indicates that the parent should pick up the seccomp fd with pidfd_getfd()
 // and send it to the seccomp agent over a unix socket. It then waits for
@@ -450,38 +497,141 @@ func setupNetwork(config *initConfig) error {
 		if err := strategy.initialize(config); err != nil {
 			return err
 		}
+		if hasShaping(&config.Network) {
+			link, err := netlink.LinkByName(config.Name)
+			if err != nil {
+				return err
+			}
+			if err := setupShaping(link, &config.Network, false); err != nil {
+				return err
+			}
+		}
 	}
 	return nil
 }
 
+// setupRoute adds the configured routes to the routing table of the
+// container network namespace.
 func setupRoute(config *configs.Config) error {
-	for _, config := range config.Routes {
-		_, dst, err := net.ParseCIDR(config.Destination)
+	for _, r := range config.Routes {
+		route, err := toNetlinkRoute(r)
 		if err != nil {
-			return err
+			return fmt.Errorf("invalid route %s: %w", routeString(r), err)
+		}
+		if err := netlink.RouteAdd(route); err != nil {
+			return fmt.Errorf("unable to add route %s: %w", routeString(r), err)
+		}
+	}
+	return nil
+}
+
+// toNetlinkRoute converts r to a netlink route. Omitted destination,
+// source, and gateway use the defaults of the route IP family, so that
+// for example a route with just a gateway is a default route.
+func toNetlinkRoute(r *configs.Route) (*netlink.Route, error) {
+	route := &netlink.Route{
+		Scope:    netlink.SCOPE_UNIVERSE,
+		Priority: r.Metric,
+	}
+	if r.Destination != "" {
+		_, dst, err := net.ParseCIDR(r.Destination)
+		if err != nil {
+			return nil, err
 		}
-		src := net.ParseIP(config.Source)
+		route.Dst = dst
+	}
+	if r.Source != "" {
+		// Source is documented as being in CIDR form, but only the
+		// address is meaningful for the preferred source.
+		src := net.ParseIP(r.Source)
 		if src == nil {
-			return fmt.Errorf("Invalid source for route: %s", config.Source)
+			ip, _, err := net.ParseCIDR(r.Source)
+			if err != nil {
+				return nil, fmt.Errorf("invalid source %q", r.Source)
+			}
+			src = ip
 		}
-		gw := net.ParseIP(config.Gateway)
+		route.Src = src
+	}
+	if r.Gateway != "" {
+		gw := net.ParseIP(r.Gateway)
 		if gw == nil {
-			return fmt.Errorf("Invalid gateway for route: %s", config.Gateway)
+			return nil, fmt.Errorf("invalid gateway %q", r.Gateway)
 		}
-		l, err := netlink.LinkByName(config.InterfaceName)
+		route.Gw = gw
+	}
+	if r.InterfaceName != "" {
+		l, err := netlink.LinkByName(r.InterfaceName)
 		if err != nil {
-			return err
-		}
-		route := &netlink.Route{
-			Scope:     netlink.SCOPE_UNIVERSE,
-			Dst:       dst,
-			Src:       src,
-			Gw:        gw,
-			LinkIndex: l.Attrs().Index,
+			return nil, err
 		}
-		if err := netlink.RouteAdd(route); err != nil {
-			return err
+		route.LinkIndex = l.Attrs().Index
+	}
+	if route.Gw == nil {
+		if route.LinkIndex == 0 {
+			return nil, errors.New("either gateway or interface name is required")
 		}
+		// Without a gateway the destination is directly reachable.
+		route.Scope = netlink.SCOPE_LINK
+	}
+	return route, nil
+}
+
+func routeString(r *configs.Route) string {
+	dst := r.Destination
+	if dst == "" {
+		dst = "default"
+	}
+	s := dst
+	if r.Gateway != "" {
+		s += " via " + r.Gateway
+	}
+	if r.InterfaceName != "" {
+		s += " dev " + r.InterfaceName
+	}
+	if r.Source != "" {
+		s += " src " + r.Source
+	}
+	if r.Metric != 0 {
+		s += " metric " + strconv.Itoa(r.Metric)
+	}
+	return s
+}
+
+// setupScheduler sets the scheduling policy and attributes of the current
+// process.
+func setupScheduler(scheduler *configs.Scheduler) error {
+	attr, err := configs.ToSchedAttr(scheduler)
+	if err != nil {
+		return err
+	}
+	if err := system.SchedSetAttr(0, attr, 0); err != nil {
+		return fmt.Errorf("error setting scheduler: %w", err)
+	}
+	return nil
+}
+
+// setupIOPriority sets the I/O scheduling class and priority of the
+// current process.
+func setupIOPriority(ioPriority *configs.IOPriority) error {
+	ioprio, err := configs.ToIOPrio(ioPriority)
+	if err != nil {
+		return err
+	}
+	if err := system.IoprioSet(system.IOPRIO_WHO_PROCESS, 0, ioprio); err != nil {
+		return fmt.Errorf("error setting io priority: %w", err)
+	}
+	return nil
+}
+
+// setupPersonality sets the execution domain of the current process.
+func setupPersonality(config *configs.Config) error {
+	personality := config.Personality.Domain
+	for _, f := range config.Personality.Flags {
+		personality |= f
+	}
+	if err := system.SetPersonality(personality); err != nil {
+		return fmt.Errorf("error setting personality: %w", err)
 	}
 	return nil
 }
@@ -9,6 +9,7 @@ import (
 	"os/exec"
 	"path/filepath"
 	"reflect"
+	"runtime"
 	"strconv"
 	"strings"
 	"syscall"
@@ -1000,6 +1001,43 @@ func TestOomScoreAdj(t *testing.T) {
 	}
 }
 
+func TestParentDeathSignalCPUAffinity(t *testing.T) {
+	if testing.Short() {
+		return
+	}
+
+	config := newTemplateConfig(t, nil)
+	config.ParentDeathSignal = int(unix.SIGKILL)
+
+	container, err := newContainer(t, config)
+	ok(t, err)
+	defer destroyContainer(container)
+
+	// The parent death signal must not be sent once the process is
+	// started, as it would be if it was started from a thread which exits
+	// then, while it still sets its initial CPU affinity. Such a thread
+	// is only terminated if it is not the main one, which is more likely
+	// with several Ps, even on a single CPU.
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(4))
+	var stdout bytes.Buffer
+	pconfig := libcontainer.Process{
+		Cwd:         "/",
+		Args:        []string{"sh", "-c", "sleep 1; grep Cpus_allowed_list /proc/self/status"},
+		Env:         standardEnvironment,
+		Stdin:       nil,
+		Stdout:      &stdout,
+		Init:        true,
+		CPUAffinity: &configs.CPUAffinity{Initial: "0"},
+	}
+	err = container.Run(&pconfig)
+	ok(t, err)
+
+	waitProcess(&pconfig, t)
+	if !strings.HasPrefix(stdout.String(), "Cpus_allowed_list:") {
+		t.Fatalf("unexpected output %q", stdout.String())
+	}
+}
+
 func TestHook(t *testing.T) {
 	if testing.Short() {
 		return
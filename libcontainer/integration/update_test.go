@@ -2,12 +2,19 @@ package integration
 
 import (
 	"bytes"
+	"errors"
 	"os"
+	"runtime"
+	"strconv"
 	"strings"
 	"testing"
 
+	"github.com/vishvananda/netlink"
+	"golang.org/x/sys/unix"
+
 	"github.com/opencontainers/runc/libcontainer"
 	"github.com/opencontainers/runc/libcontainer/cgroups/systemd"
+	"github.com/opencontainers/runc/libcontainer/configs"
 	"github.com/opencontainers/runc/libcontainer/devices"
 )
 
@@ -96,3 +103,110 @@ func TestUpdateDevicesSystemd(t *testing.T) {
 	}
 	testUpdateDevices(t, true)
 }
+
+// egressRate returns the rate of the tbf root qdisc of the name interface
+// in the network namespace at nsPath.
+func egressRate(nsPath, name string) (uint64, error) {
+	var (
+		rate uint64
+		err  error
+	)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		// The thread is not returned to the Go runtime, as it is left in
+		// the container network namespace.
+		runtime.LockOSThread()
+		var fd int
+		fd, err = unix.Open(nsPath, unix.O_RDONLY|unix.O_CLOEXEC, 0)
+		if err != nil {
+			return
+		}
+		defer unix.Close(fd)
+		if err = unix.Setns(fd, unix.CLONE_NEWNET); err != nil {
+			return
+		}
+		var link netlink.Link
+		link, err = netlink.LinkByName(name)
+		if err != nil {
+			return
+		}
+		var qdiscs []netlink.Qdisc
+		qdiscs, err = netlink.QdiscList(link)
+		if err != nil {
+			return
+		}
+		for _, q := range qdiscs {
+			if tbf, ok := q.(*netlink.Tbf); ok && tbf.Parent == netlink.HANDLE_ROOT {
+				rate = tbf.Rate
+				return
+			}
+		}
+		err = errors.New("no tbf root qdisc on " + name)
+	}()
+	<-done
+	return rate, err
+}
+
+func TestSetNetworkBandwidth(t *testing.T) {
+	if testing.Short() {
+		return
+	}
+	config := newTemplateConfig(t, &tParam{})
+	config.Networks = append(config.Networks, &configs.Network{
+		Type:              "veth",
+		Name:              "eth0",
+		HostInterfaceName: "rtest" + strconv.Itoa(os.Getpid()),
+		Egress:            &configs.Bandwidth{Rate: 1 << 20},
+	})
+	container, err := newContainer(t, config)
+	ok(t, err)
+	defer destroyContainer(container)
+
+	stdinR, stdinW, err := os.Pipe()
+	ok(t, err)
+	process := &libcontainer.Process{
+		Cwd:   "/",
+		Args:  []string{"cat"},
+		Env:   standardEnvironment,
+		Stdin: stdinR,
+		Init:  true,
+	}
+	err = container.Run(process)
+	_ = stdinR.Close()
+	defer func() {
+		_ = stdinW.Close()
+		if _, err := process.Wait(); err != nil {
+			t.Log(err)
+		}
+	}()
+	ok(t, err)
+
+	state, err := container.State()
+	ok(t, err)
+	nsPath := state.NamespacePaths[configs.NEWNET]
+	rate, err := egressRate(nsPath, "eth0")
+	ok(t, err)
+	if rate != 1<<20 {
+		t.Fatalf("expected egress rate %d, got %d", 1<<20, rate)
+	}
+
+	// The networks of the current config are shared with the container, so
+	// they are copied before being changed (as runc update does).
+	newConfig := container.Config()
+	newConfig.Networks = append([]*configs.Network(nil), newConfig.Networks...)
+	for i, n := range newConfig.Networks {
+		if n.Type == "veth" {
+			nc := *n
+			nc.Egress = &configs.Bandwidth{Rate: 1 << 21}
+			newConfig.Networks[i] = &nc
+		}
+	}
+	ok(t, container.Set(newConfig))
+
+	rate, err = egressRate(nsPath, "eth0")
+	ok(t, err)
+	if rate != 1<<21 {
+		t.Fatalf("expected egress rate %d after update, got %d", 1<<21, rate)
+	}
+}
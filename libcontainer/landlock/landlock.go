@@ -0,0 +1,124 @@
+// Package landlock applies Landlock rulesets to container processes.
+package landlock
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/opencontainers/runc/libcontainer/configs"
+)
+
+// Filesystem access rights, as defined in <linux/landlock.h>.
+const (
+	accessFSExecute    = 1 << 0
+	accessFSWriteFile  = 1 << 1
+	accessFSReadFile   = 1 << 2
+	accessFSReadDir    = 1 << 3
+	accessFSRemoveDir  = 1 << 4
+	accessFSRemoveFile = 1 << 5
+	accessFSMakeChar   = 1 << 6
+	accessFSMakeDir    = 1 << 7
+	accessFSMakeReg    = 1 << 8
+	accessFSMakeSock   = 1 << 9
+	accessFSMakeFifo   = 1 << 10
+	accessFSMakeBlock  = 1 << 11
+	accessFSMakeSym    = 1 << 12
+	accessFSRefer      = 1 << 13
+	accessFSTruncate   = 1 << 14
+	accessFSIoctlDev   = 1 << 15
+
+	// accessFile is the set of access rights which can be allowed for a
+	// non-directory file.
+	accessFile = accessFSExecute | accessFSWriteFile | accessFSReadFile | accessFSTruncate | accessFSIoctlDev
+
+	// maxABI is the latest Landlock ABI version known to runc.
+	maxABI = 5
+)
+
+type accessRight struct {
+	bit uint64
+	// abi is the Landlock ABI version which introduced the right.
+	abi int
+}
+
+var accessFS = map[string]accessRight{
+	"execute":     {accessFSExecute, 1},
+	"write_file":  {accessFSWriteFile, 1},
+	"read_file":   {accessFSReadFile, 1},
+	"read_dir":    {accessFSReadDir, 1},
+	"remove_dir":  {accessFSRemoveDir, 1},
+	"remove_file": {accessFSRemoveFile, 1},
+	"make_char":   {accessFSMakeChar, 1},
+	"make_dir":    {accessFSMakeDir, 1},
+	"make_reg":    {accessFSMakeReg, 1},
+	"make_sock":   {accessFSMakeSock, 1},
+	"make_fifo":   {accessFSMakeFifo, 1},
+	"make_block":  {accessFSMakeBlock, 1},
+	"make_sym":    {accessFSMakeSym, 1},
+	"refer":       {accessFSRefer, 2},
+	"truncate":    {accessFSTruncate, 3},
+	"ioctl_dev":   {accessFSIoctlDev, 5},
+}
+
+// KnownAccessFS returns the list of the known filesystem access rights.
+// Used by `runc features`.
+func KnownAccessFS() []string {
+	var res []string
+	for k := range accessFS {
+		res = append(res, k)
+	}
+	sort.Strings(res)
+	return res
+}
+
+// ErrLandlockNotSupported indicates that Landlock is not supported by the
+// running kernel.
+var ErrLandlockNotSupported = errors.New("landlock: config provided but landlock not supported")
+
+// parseAccessFS converts the access right names to a bitmask. Rights which
+// were introduced after the given ABI version are left out of the result.
+func parseAccessFS(names []string, abi int) (uint64, error) {
+	var access uint64
+	for _, name := range names {
+		r, ok := accessFS[name]
+		if !ok {
+			return 0, fmt.Errorf("landlock: unknown filesystem access right %q", name)
+		}
+		if r.abi <= abi {
+			access |= r.bit
+		}
+	}
+	return access, nil
+}
+
+// Validate checks that the ruleset only uses known access rights and that
+// all its rules have paths.
+func Validate(l *configs.Landlock) error {
+	if l.ABI < 0 {
+		return fmt.Errorf("landlock: invalid ABI version %d", l.ABI)
+	}
+	if len(l.HandledAccessFS) == 0 {
+		return errors.New("landlock: no handled filesystem access rights")
+	}
+	if l.ABI > maxABI {
+		return fmt.Errorf("landlock: unknown ABI version %d", l.ABI)
+	}
+	if _, err := parseAccessFS(l.HandledAccessFS, maxABI); err != nil {
+		return err
+	}
+	for _, rule := range l.Rules {
+		if len(rule.Paths) == 0 {
+			return errors.New("landlock: rule without paths")
+		}
+		for _, p := range rule.Paths {
+			if p == "" {
+				return errors.New("landlock: rule with an empty path")
+			}
+		}
+		if _, err := parseAccessFS(rule.AllowedAccess, maxABI); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+package landlock
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/sys/unix"
+
+	"github.com/opencontainers/runc/libcontainer/configs"
+	"github.com/opencontainers/runc/libcontainer/system"
+)
+
+var (
+	abiVersion int
+	checkABI   sync.Once
+)
+
+// ABIVersion returns the Landlock ABI version supported by the running
+// kernel, or 0 if Landlock is not supported (or disabled).
+func ABIVersion() int {
+	checkABI.Do(func() {
+		v, err := system.LandlockCreateRuleset(nil, unix.LANDLOCK_CREATE_RULESET_VERSION)
+		if err == nil {
+			abiVersion = v
+		}
+	})
+	return abiVersion
+}
+
+// Ruleset is a Landlock ruleset prepared for enforcement.
+type Ruleset struct {
+	fd int
+}
+
+// Prepare creates the Landlock ruleset described by l and adds its rules.
+// The paths of the rules are resolved in the current mount namespace. It
+// returns a nil ruleset if there is nothing to enforce, i.e. if l is nil,
+// or if the kernel does not support Landlock and l.ABI is 0.
+//
+// Preparing the ruleset separately from enforcing it lets the caller do
+// the bulk of the work early, and only restrict itself right before exec.
+func Prepare(l *configs.Landlock) (*Ruleset, error) {
+	if l == nil {
+		return nil, nil
+	}
+	abi := ABIVersion()
+	if abi < l.ABI {
+		if abi == 0 {
+			return nil, ErrLandlockNotSupported
+		}
+		return nil, fmt.Errorf("landlock: ABI version %d required, but the kernel only supports version %d", l.ABI, abi)
+	}
+	if abi == 0 {
+		logrus.Warn("landlock: not supported by the kernel, the ruleset is not enforced")
+		return nil, nil
+	}
+	handled, err := parseAccessFS(l.HandledAccessFS, abi)
+	if err != nil {
+		return nil, err
+	}
+	if handled == 0 {
+		return nil, nil
+	}
+	// landlock_restrict_self(2) requires either no_new_privs or
+	// CAP_SYS_ADMIN; check for it now to report a meaningful error.
+	if err := checkRestrictSelf(); err != nil {
+		return nil, err
+	}
+
+	fd, err := system.LandlockCreateRuleset(&unix.LandlockRulesetAttr{Access_fs: handled}, 0)
+	if err != nil {
+		return nil, err
+	}
+	r := &Ruleset{fd: fd}
+	for _, rule := range l.Rules {
+		allowed, err := parseAccessFS(rule.AllowedAccess, abi)
+		if err != nil {
+			r.Close()
+			return nil, err
+		}
+		allowed &= handled
+		if allowed == 0 {
+			continue
+		}
+		for _, path := range rule.Paths {
+			if err := r.addPath(path, allowed); err != nil {
+				r.Close()
+				return nil, err
+			}
+		}
+	}
+	return r, nil
+}
+
+func (r *Ruleset) addPath(path string, allowed uint64) error {
+	fd, err := unix.Open(path, unix.O_PATH|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return &os.PathError{Op: "open", Path: path, Err: err}
+	}
+	defer unix.Close(fd)
+
+	var st unix.Stat_t
+	if err := unix.Fstat(fd, &st); err != nil {
+		return &os.PathError{Op: "fstat", Path: path, Err: err}
+	}
+	if st.Mode&unix.S_IFMT != unix.S_IFDIR {
+		// Directory-only rights can't be allowed for a file.
+		allowed &= accessFile
+		if allowed == 0 {
+			return nil
+		}
+	}
+	err = system.LandlockAddPathBeneathRule(r.fd, &unix.LandlockPathBeneathAttr{
+		Allowed_access: allowed,
+		Parent_fd:      int32(fd),
+	})
+	if err != nil {
+		return fmt.Errorf("landlock: unable to add rule for %s: %w", path, err)
+	}
+	return nil
+}
+
+// Enforce restricts the calling thread, and its future children, with the
+// ruleset, then closes it. A nil ruleset is a no-op.
+func (r *Ruleset) Enforce() error {
+	if r == nil {
+		return nil
+	}
+	defer r.Close()
+	if err := system.LandlockRestrictSelf(r.fd); err != nil {
+		return fmt.Errorf("landlock: unable to enforce ruleset: %w", err)
+	}
+	return nil
+}
+
+// Close releases the ruleset without enforcing it.
+func (r *Ruleset) Close() {
+	if r != nil && r.fd != -1 {
+		_ = unix.Close(r.fd)
+		r.fd = -1
+	}
+}
+
+func checkRestrictSelf() error {
+	nnp, err := unix.PrctlRetInt(unix.PR_GET_NO_NEW_PRIVS, 0, 0, 0, 0)
+	if err != nil {
+		return os.NewSyscallError("prctl", err)
+	}
+	if nnp == 1 {
+		return nil
+	}
+	hdr := unix.CapUserHeader{Version: unix.LINUX_CAPABILITY_VERSION_3}
+	var data [2]unix.CapUserData
+	if err := unix.Capget(&hdr, &data[0]); err != nil {
+		return os.NewSyscallError("capget", err)
+	}
+	if data[0].Effective&(1<<unix.CAP_SYS_ADMIN) != 0 {
+		return nil
+	}
+	return errors.New("landlock: enforcing a ruleset requires noNewPrivileges or CAP_SYS_ADMIN")
+}
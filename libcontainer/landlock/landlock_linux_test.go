@@ -0,0 +1,77 @@
+package landlock
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/opencontainers/runc/libcontainer/configs"
+)
+
+func TestPrepareAndEnforce(t *testing.T) {
+	if ABIVersion() == 0 {
+		t.Skip("landlock not supported")
+	}
+	dir := t.TempDir()
+	allowed := filepath.Join(dir, "allowed")
+	denied := filepath.Join(dir, "denied")
+	for _, d := range []string{allowed, denied} {
+		if err := os.Mkdir(d, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(d, "file"), []byte("data"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	errCh := make(chan error)
+	go func() {
+		// Landlock and no_new_privs only apply to the calling thread,
+		// which is thrown away once this goroutine returns since it is
+		// never unlocked.
+		runtime.LockOSThread()
+		if err := unix.Prctl(unix.PR_SET_NO_NEW_PRIVS, 1, 0, 0, 0); err != nil {
+			errCh <- err
+			return
+		}
+		r, err := Prepare(&configs.Landlock{
+			HandledAccessFS: []string{"read_file", "write_file"},
+			Rules: []configs.LandlockRule{
+				{Paths: []string{allowed}, AllowedAccess: []string{"read_file"}},
+			},
+			ABI: 1,
+		})
+		if err != nil {
+			errCh <- err
+			return
+		}
+		if err := r.Enforce(); err != nil {
+			errCh <- err
+			return
+		}
+		fd, err := unix.Open(filepath.Join(allowed, "file"), unix.O_RDONLY|unix.O_CLOEXEC, 0)
+		if err != nil {
+			errCh <- err
+			return
+		}
+		_ = unix.Close(fd)
+		for _, p := range []string{filepath.Join(allowed, "file"), filepath.Join(denied, "file")} {
+			mode := unix.O_RDONLY
+			if filepath.Dir(p) == allowed {
+				mode = unix.O_WRONLY
+			}
+			if _, err := unix.Open(p, mode|unix.O_CLOEXEC, 0); !errors.Is(err, unix.EACCES) {
+				errCh <- &os.PathError{Op: "expected EACCES opening", Path: p, Err: err}
+				return
+			}
+		}
+		errCh <- nil
+	}()
+	if err := <-errCh; err != nil {
+		t.Fatal(err)
+	}
+}
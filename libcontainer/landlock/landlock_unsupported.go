@@ -0,0 +1,30 @@
+//go:build !linux
+// +build !linux
+
+package landlock
+
+import "github.com/opencontainers/runc/libcontainer/configs"
+
+// ABIVersion returns the Landlock ABI version supported by the running
+// kernel, or 0 if Landlock is not supported.
+func ABIVersion() int {
+	return 0
+}
+
+// Ruleset is a Landlock ruleset prepared for enforcement.
+type Ruleset struct{}
+
+// Prepare creates the Landlock ruleset described by l. It is only
+// supported on Linux and produces an ErrLandlockNotSupported on other
+// platforms, unless the ruleset allows best-effort enforcement.
+func Prepare(l *configs.Landlock) (*Ruleset, error) {
+	if l != nil && l.ABI > 0 {
+		return nil, ErrLandlockNotSupported
+	}
+	return nil, nil
+}
+
+// Enforce restricts the calling thread with the ruleset.
+func (r *Ruleset) Enforce() error {
+	return nil
+}
@@ -1,9 +1,15 @@
 package libcontainer
 
 import (
+	"fmt"
+	"os"
 	"strconv"
 
 	"golang.org/x/sys/unix"
+
+	"github.com/opencontainers/runc/libcontainer/configs"
+	"github.com/opencontainers/runc/libcontainer/system"
+	"github.com/opencontainers/runc/libcontainer/userns"
 )
 
 // mountError holds an error from a failed mount or unmount operation.
@@ -81,3 +87,57 @@ func unmount(target string, flags int) error {
 	}
 	return nil
 }
+
+// moveMount attaches the detached mount referenced by fd to target. If procfd
+// is not empty, it is used instead of target (and the target is only used to
+// add context to an error).
+func moveMount(fd int, source, target, procfd string) error {
+	dst := target
+	if procfd != "" {
+		dst = procfd
+	}
+	if err := system.MoveMount(fd, "", -1, dst, system.MOVE_MOUNT_F_EMPTY_PATH); err != nil {
+		return &mountError{
+			op:     "move_mount",
+			source: source,
+			target: target,
+			procfd: procfd,
+			err:    err,
+		}
+	}
+	return nil
+}
+
+// createIDMappedMount clones the source of m into a detached mount and
+// applies the ID mapping of m to it. The returned file can then be attached
+// anywhere with moveMount.
+func createIDMappedMount(m *configs.Mount) (*os.File, error) {
+	usernsFile, err := userns.GetUserNamespace(m.IDMapping.UIDMappings, m.IDMapping.GIDMappings)
+	if err != nil {
+		return nil, err
+	}
+	defer usernsFile.Close()
+
+	flags := uint(system.OPEN_TREE_CLONE | unix.O_CLOEXEC)
+	if m.Flags&unix.MS_REC != 0 {
+		flags |= unix.AT_RECURSIVE
+	}
+	fd, err := system.OpenTree(unix.AT_FDCWD, m.Source, flags)
+	if err != nil {
+		return nil, &os.PathError{Op: "open_tree", Path: m.Source, Err: err}
+	}
+
+	setattrFlags := uint(unix.AT_EMPTY_PATH)
+	if m.IDMapping.Recursive {
+		setattrFlags |= unix.AT_RECURSIVE
+	}
+	if err := unix.MountSetattr(fd, "", setattrFlags, &unix.MountAttr{
+		Attr_set:  unix.MOUNT_ATTR_IDMAP,
+		Userns_fd: uint64(usernsFile.Fd()),
+	}); err != nil {
+		unix.Close(fd)
+		return nil, fmt.Errorf("failed to set MOUNT_ATTR_IDMAP on %s: %w", m.Source, err)
+	}
+
+	return os.NewFile(uintptr(fd), "idmap:"+m.Source), nil
+}
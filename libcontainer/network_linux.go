@@ -2,17 +2,27 @@ package libcontainer
 
 import (
 	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"net"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strconv"
+	"strings"
 
 	"github.com/opencontainers/runc/libcontainer/configs"
 	"github.com/opencontainers/runc/types"
 	"github.com/vishvananda/netlink"
+	"golang.org/x/sys/unix"
 )
 
 var strategies = map[string]networkStrategy{
+	"veth":     &veth{},
+	"macvlan":  &macvlan{},
+	"ipvlan":   &ipvlan{},
 	"loopback": &loopback{},
 }
 
@@ -23,6 +33,7 @@ type networkStrategy interface {
 	initialize(*network) error
 	detach(*configs.Network) error
 	attach(*configs.Network) error
+	destroy(*configs.Network) error
 }
 
 // getStrategy returns the specific network strategy for the
@@ -71,6 +82,83 @@ func getNetworkInterfaceStats(interfaceName string) (*types.NetworkInterface, er
 	return out, nil
 }
 
+// getNetnsInterfaceStats returns the statistics of all the network
+// interfaces in the network namespace of the process pid.
+func getNetnsInterfaceStats(pid int) ([]*types.NetworkInterface, error) {
+	var out []*types.NetworkInterface
+	err := runInNetns(pid, func() error {
+		links, err := netlink.LinkList()
+		if err != nil {
+			return err
+		}
+		drops, err := getQdiscDrops()
+		if err != nil {
+			return err
+		}
+		for _, link := range links {
+			attrs := link.Attrs()
+			s := &types.NetworkInterface{Name: attrs.Name}
+			if st := attrs.Statistics; st != nil {
+				s.RxBytes = st.RxBytes
+				s.RxPackets = st.RxPackets
+				s.RxErrors = st.RxErrors
+				s.RxDropped = st.RxDropped
+				s.TxBytes = st.TxBytes
+				s.TxPackets = st.TxPackets
+				s.TxErrors = st.TxErrors
+				s.TxDropped = st.TxDropped
+				s.Multicast = st.Multicast
+				s.Collisions = st.Collisions
+				s.RxLengthErrors = st.RxLengthErrors
+				s.RxOverErrors = st.RxOverErrors
+				s.RxCrcErrors = st.RxCrcErrors
+				s.RxFrameErrors = st.RxFrameErrors
+				s.RxFifoErrors = st.RxFifoErrors
+				s.RxMissedErrors = st.RxMissedErrors
+				s.TxAbortedErrors = st.TxAbortedErrors
+				s.TxCarrierErrors = st.TxCarrierErrors
+				s.TxFifoErrors = st.TxFifoErrors
+				s.TxHeartbeatErrors = st.TxHeartbeatErrors
+				s.TxWindowErrors = st.TxWindowErrors
+				s.RxCompressed = st.RxCompressed
+				s.TxCompressed = st.TxCompressed
+			}
+			if d := drops[attrs.Index]; d != nil {
+				s.TxQdiscDropped = d.egress
+				s.RxQdiscDropped = d.ingress
+			}
+			out = append(out, s)
+		}
+		return nil
+	})
+	return out, err
+}
+
+// runInNetns runs fn on a dedicated OS thread in the network namespace of
+// the process pid.
+func runInNetns(pid int, fn func() error) error {
+	errCh := make(chan error, 1)
+	go func() {
+		// The thread is never unlocked, so it is terminated rather than
+		// reused once the goroutine exits.
+		runtime.LockOSThread()
+		path := "/proc/" + strconv.Itoa(pid) + "/ns/net"
+		fd, err := unix.Open(path, unix.O_RDONLY|unix.O_CLOEXEC, 0)
+		if err != nil {
+			errCh <- &os.PathError{Op: "open", Path: path, Err: err}
+			return
+		}
+		err = unix.Setns(fd, unix.CLONE_NEWNET)
+		unix.Close(fd)
+		if err != nil {
+			errCh <- os.NewSyscallError("setns", err)
+			return
+		}
+		errCh <- fn()
+	}()
+	return <-errCh
+}
+
 // Reads the specified statistics available under /sys/class/net/<EthInterface>/statistics
 func readSysfsNetworkStats(ethInterface, statsFile string) (uint64, error) {
 	data, err := os.ReadFile(filepath.Join("/sys/class/net", ethInterface, "statistics", statsFile))
@@ -98,3 +186,353 @@ func (l *loopback) attach(n *configs.Network) (err error) {
 func (l *loopback) detach(n *configs.Network) (err error) {
 	return nil
 }
+
+func (l *loopback) destroy(n *configs.Network) error {
+	return nil
+}
+
+// veth is a network strategy that creates a veth pair, one end of which
+// is moved into the container and renamed, while the other one stays on
+// the host side and is optionally attached to a bridge.
+type veth struct{}
+
+func (v *veth) create(n *network, nspid int) (err error) {
+	if n.HostInterfaceName == "" {
+		return errors.New("veth: host interface name is not specified")
+	}
+	peer, err := generateTempName("veth")
+	if err != nil {
+		return err
+	}
+	n.TempVethPeerName = peer
+	attrs := netlink.NewLinkAttrs()
+	attrs.Name = n.HostInterfaceName
+	attrs.MTU = n.Mtu
+	if n.TxQueueLen > 0 {
+		attrs.TxQLen = n.TxQueueLen
+	}
+	link := &netlink.Veth{LinkAttrs: attrs, PeerName: peer}
+	if err := netlink.LinkAdd(link); err != nil {
+		return fmt.Errorf("veth: unable to create %s: %w", n.HostInterfaceName, err)
+	}
+	defer func() {
+		if err != nil {
+			_ = netlink.LinkDel(link)
+		}
+	}()
+	host, err := netlink.LinkByName(n.HostInterfaceName)
+	if err != nil {
+		return fmt.Errorf("veth: %w", err)
+	}
+	n.HostInterfaceIndex = host.Attrs().Index
+	if err := v.attach(&n.Network); err != nil {
+		return err
+	}
+	child, err := netlink.LinkByName(peer)
+	if err != nil {
+		return fmt.Errorf("veth: %w", err)
+	}
+	if err := netlink.LinkSetNsPid(child, nspid); err != nil {
+		return fmt.Errorf("veth: unable to move %s to the container: %w", peer, err)
+	}
+	return nil
+}
+
+func (v *veth) initialize(config *network) error {
+	if config.TempVethPeerName == "" {
+		return errors.New("veth: peer is not specified")
+	}
+	child, err := netlink.LinkByName(config.TempVethPeerName)
+	if err != nil {
+		return fmt.Errorf("veth: %w", err)
+	}
+	return configureInterface(child, config)
+}
+
+// attach attaches the host side of the veth pair to the bridge, if any,
+// and brings it up.
+func (v *veth) attach(n *configs.Network) error {
+	host, err := netlink.LinkByName(n.HostInterfaceName)
+	if err != nil {
+		return fmt.Errorf("veth: %w", err)
+	}
+	if n.Bridge != "" {
+		br, err := netlink.LinkByName(n.Bridge)
+		if err != nil {
+			return fmt.Errorf("veth: %w", err)
+		}
+		if _, ok := br.(*netlink.Bridge); !ok {
+			return fmt.Errorf("veth: %s is not a bridge but %s", n.Bridge, br.Type())
+		}
+		if err := netlink.LinkSetMaster(host, br); err != nil {
+			return fmt.Errorf("veth: unable to attach %s to %s: %w", n.HostInterfaceName, n.Bridge, err)
+		}
+		if n.HairpinMode {
+			if err := netlink.LinkSetHairpin(host, true); err != nil {
+				return fmt.Errorf("veth: unable to set hairpin mode on %s: %w", n.HostInterfaceName, err)
+			}
+		}
+	}
+	if err := netlink.LinkSetUp(host); err != nil {
+		return fmt.Errorf("veth: unable to bring %s up: %w", n.HostInterfaceName, err)
+	}
+	return nil
+}
+
+// detach detaches the host side of the veth pair from the bridge, if any.
+func (v *veth) detach(n *configs.Network) error {
+	if n.Bridge == "" {
+		return nil
+	}
+	host, err := netlink.LinkByName(n.HostInterfaceName)
+	if err != nil {
+		return fmt.Errorf("veth: %w", err)
+	}
+	return netlink.LinkSetNoMaster(host)
+}
+
+// destroy removes the veth pair. It is usually gone already, as it is
+// removed by the kernel together with the container network namespace, in
+// which case its name may have been reused by another interface since.
+func (v *veth) destroy(n *configs.Network) error {
+	host, err := netlink.LinkByName(n.HostInterfaceName)
+	if err != nil {
+		var notFound netlink.LinkNotFoundError
+		if errors.As(err, &notFound) {
+			return nil
+		}
+		return fmt.Errorf("veth: %w", err)
+	}
+	if _, ok := host.(*netlink.Veth); !ok {
+		return nil
+	}
+	// The index is not known for the containers created by older versions.
+	if n.HostInterfaceIndex != 0 && host.Attrs().Index != n.HostInterfaceIndex {
+		return nil
+	}
+	return netlink.LinkDel(host)
+}
+
+// macvlan is a network strategy that creates a macvlan interface on the
+// parent host interface, and moves it into the container.
+type macvlan struct{}
+
+var macvlanModes = map[string]netlink.MacvlanMode{
+	configs.MacvlanModeBridge:  netlink.MACVLAN_MODE_BRIDGE,
+	configs.MacvlanModePrivate: netlink.MACVLAN_MODE_PRIVATE,
+	configs.MacvlanModeVEPA:    netlink.MACVLAN_MODE_VEPA,
+}
+
+func (m *macvlan) create(n *network, nspid int) error {
+	name, err := n.ModeOrDefault()
+	if err != nil {
+		return err
+	}
+	mode, ok := macvlanModes[name]
+	if !ok {
+		return fmt.Errorf("macvlan: unsupported mode %q", name)
+	}
+	return createSubInterface(n, nspid, "mv", func(attrs netlink.LinkAttrs) netlink.Link {
+		return &netlink.Macvlan{LinkAttrs: attrs, Mode: mode}
+	})
+}
+
+func (m *macvlan) initialize(config *network) error {
+	return initializeSubInterface(config)
+}
+
+func (m *macvlan) attach(n *configs.Network) error {
+	return nil
+}
+
+func (m *macvlan) detach(n *configs.Network) error {
+	return nil
+}
+
+// destroy is a no-op, as the interface lives in the container network
+// namespace and is removed by the kernel together with it.
+func (m *macvlan) destroy(n *configs.Network) error {
+	return nil
+}
+
+// ipvlan is a network strategy that creates an ipvlan interface on the
+// parent host interface, and moves it into the container.
+type ipvlan struct{}
+
+var ipvlanModes = map[string]netlink.IPVlanMode{
+	configs.IPVlanModeL2: netlink.IPVLAN_MODE_L2,
+	configs.IPVlanModeL3: netlink.IPVLAN_MODE_L3,
+}
+
+func (i *ipvlan) create(n *network, nspid int) error {
+	name, err := n.ModeOrDefault()
+	if err != nil {
+		return err
+	}
+	mode, ok := ipvlanModes[name]
+	if !ok {
+		return fmt.Errorf("ipvlan: unsupported mode %q", name)
+	}
+	return createSubInterface(n, nspid, "ipv", func(attrs netlink.LinkAttrs) netlink.Link {
+		return &netlink.IPVlan{LinkAttrs: attrs, Mode: mode}
+	})
+}
+
+func (i *ipvlan) initialize(config *network) error {
+	return initializeSubInterface(config)
+}
+
+func (i *ipvlan) attach(n *configs.Network) error {
+	return nil
+}
+
+func (i *ipvlan) detach(n *configs.Network) error {
+	return nil
+}
+
+// destroy is a no-op, as the interface lives in the container network
+// namespace and is removed by the kernel together with it.
+func (i *ipvlan) destroy(n *configs.Network) error {
+	return nil
+}
+
+// createSubInterface creates a link of the kind returned by newLink on top
+// of the n.Parent host interface, using a temporary name, and moves it into
+// the network namespace of nspid. The link is removed if it can't be moved.
+func createSubInterface(n *network, nspid int, prefix string, newLink func(netlink.LinkAttrs) netlink.Link) (err error) {
+	kind := n.Type
+	if n.Parent == "" {
+		return fmt.Errorf("%s: parent interface is not specified", kind)
+	}
+	parent, err := netlink.LinkByName(n.Parent)
+	if err != nil {
+		return fmt.Errorf("%s: %w", kind, err)
+	}
+	name, err := generateTempName(prefix)
+	if err != nil {
+		return err
+	}
+	n.TempVethPeerName = name
+	attrs := netlink.NewLinkAttrs()
+	attrs.Name = name
+	attrs.ParentIndex = parent.Attrs().Index
+	attrs.MTU = n.Mtu
+	if n.TxQueueLen > 0 {
+		attrs.TxQLen = n.TxQueueLen
+	}
+	link := newLink(attrs)
+	if err := netlink.LinkAdd(link); err != nil {
+		return fmt.Errorf("%s: unable to create interface on %s: %w", kind, n.Parent, err)
+	}
+	defer func() {
+		if err != nil {
+			_ = netlink.LinkDel(link)
+		}
+	}()
+	if err := netlink.LinkSetNsPid(link, nspid); err != nil {
+		return fmt.Errorf("%s: unable to move %s to the container: %w", kind, name, err)
+	}
+	return nil
+}
+
+func initializeSubInterface(config *network) error {
+	if config.TempVethPeerName == "" {
+		return fmt.Errorf("%s: interface is not specified", config.Type)
+	}
+	link, err := netlink.LinkByName(config.TempVethPeerName)
+	if err != nil {
+		return fmt.Errorf("%s: %w", config.Type, err)
+	}
+	return configureInterface(link, config)
+}
+
+// generateTempName returns a random name with the given prefix for a
+// network interface, used until it is renamed inside the container.
+func generateTempName(prefix string) (string, error) {
+	id := make([]byte, 4)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	return prefix + hex.EncodeToString(id)[:7], nil
+}
+
+// configureInterface renames the network interface inside the container
+// as per config, sets its MAC address, MTU and addresses, brings it up,
+// and adds the default routes.
+func configureInterface(link netlink.Link, config *network) error {
+	if config.Name != "" && config.Name != link.Attrs().Name {
+		if err := netlink.LinkSetName(link, config.Name); err != nil {
+			return fmt.Errorf("unable to rename %s to %s: %w", link.Attrs().Name, config.Name, err)
+		}
+	}
+	if config.MacAddress != "" {
+		mac, err := net.ParseMAC(config.MacAddress)
+		if err != nil {
+			return err
+		}
+		if err := netlink.LinkSetHardwareAddr(link, mac); err != nil {
+			return fmt.Errorf("unable to set MAC address of %s: %w", config.Name, err)
+		}
+	}
+	if config.Mtu > 0 {
+		if err := netlink.LinkSetMTU(link, config.Mtu); err != nil {
+			return fmt.Errorf("unable to set MTU of %s: %w", config.Name, err)
+		}
+	}
+	for _, a := range []string{config.Address, config.IPv6Address} {
+		if a == "" {
+			continue
+		}
+		addr, err := netlink.ParseAddr(a)
+		if err != nil {
+			return err
+		}
+		if addr.IP.To4() == nil {
+			// The address is statically assigned, so skip duplicate
+			// address detection, which would otherwise keep it
+			// tentative and unusable as a route source for a while.
+			addr.Flags = unix.IFA_F_NODAD
+		}
+		if err := netlink.AddrAdd(link, addr); err != nil {
+			return fmt.Errorf("unable to add address %s to %s: %w", a, config.Name, err)
+		}
+	}
+	if err := netlink.LinkSetUp(link); err != nil {
+		return fmt.Errorf("unable to bring %s up: %w", config.Name, err)
+	}
+	for _, gw := range []string{config.Gateway, config.IPv6Gateway} {
+		if gw == "" {
+			continue
+		}
+		ip := net.ParseIP(gw)
+		if ip == nil {
+			return fmt.Errorf("invalid gateway %q", gw)
+		}
+		if err := netlink.RouteAdd(&netlink.Route{
+			Scope:     netlink.SCOPE_UNIVERSE,
+			LinkIndex: link.Attrs().Index,
+			Gw:        ip,
+		}); err != nil {
+			return fmt.Errorf("unable to add default route via %s: %w", gw, err)
+		}
+	}
+	return nil
+}
+
+// destroyNetwork cleans up the host side of the container networks.
+func destroyNetwork(config *configs.Config) error {
+	var errs []string
+	for _, n := range config.Networks {
+		strategy, err := getStrategy(n.Type)
+		if err == nil {
+			err = strategy.destroy(n)
+		}
+		if err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("unable to destroy networks: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
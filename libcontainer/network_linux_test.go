@@ -0,0 +1,391 @@
+package libcontainer
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/vishvananda/netlink"
+	"golang.org/x/sys/unix"
+
+	"github.com/opencontainers/runc/libcontainer/configs"
+)
+
+// withNetns runs fn on a locked OS thread switched into a new network
+// namespace. The thread is not returned to the Go runtime afterwards.
+func withNetns(t *testing.T, fn func()) {
+	if os.Geteuid() != 0 {
+		t.Skip("requires root")
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		runtime.LockOSThread()
+		if err := unix.Unshare(unix.CLONE_NEWNET); err != nil {
+			t.Errorf("unshare: %v", err)
+			return
+		}
+		fn()
+	}()
+	<-done
+}
+
+// withContainerNetns runs fn like withNetns, with the PID of a process in
+// another new network namespace, standing for the container init. The code
+// to run in the container network namespace can be passed to runInNetns.
+func withContainerNetns(t *testing.T, fn func(pid int)) {
+	withNetns(t, func() {
+		cmd := exec.Command("sleep", "30")
+		cmd.SysProcAttr = &unix.SysProcAttr{Cloneflags: unix.CLONE_NEWNET}
+		if err := cmd.Start(); err != nil {
+			t.Errorf("unable to start a process in a new netns: %v", err)
+			return
+		}
+		defer func() {
+			_ = cmd.Process.Kill()
+			_ = cmd.Wait()
+		}()
+		fn(cmd.Process.Pid)
+	})
+}
+
+func TestVethStrategy(t *testing.T) {
+	withContainerNetns(t, func(pid int) {
+		br := &netlink.Bridge{LinkAttrs: netlink.LinkAttrs{Name: "testbr0"}}
+		if err := netlink.LinkAdd(br); err != nil {
+			t.Errorf("bridge: %v", err)
+			return
+		}
+
+		n := &network{Network: configs.Network{
+			Type:              "veth",
+			Name:              "eth0",
+			HostInterfaceName: "testveth0",
+			Bridge:            "testbr0",
+			MacAddress:        "02:42:ac:11:00:02",
+			Address:           "172.17.0.2/16",
+			Gateway:           "172.17.0.1",
+			IPv6Address:       "fd00::2/64",
+			Mtu:               1400,
+		}}
+		v := &veth{}
+		if err := v.create(n, pid); err != nil {
+			t.Errorf("create: %v", err)
+			return
+		}
+		host, err := netlink.LinkByName("testveth0")
+		if err != nil {
+			t.Errorf("host side: %v", err)
+			return
+		}
+		if host.Attrs().MasterIndex != br.Attrs().Index {
+			t.Errorf("host side is not attached to the bridge")
+		}
+		if host.Attrs().MTU != 1400 {
+			t.Errorf("expected host side MTU 1400, got %d", host.Attrs().MTU)
+		}
+		if _, err := netlink.LinkByName(n.TempVethPeerName); err == nil {
+			t.Errorf("peer %s was not moved to the container", n.TempVethPeerName)
+		}
+
+		if host.Attrs().Index != n.HostInterfaceIndex {
+			t.Errorf("expected host side index %d, got %d", host.Attrs().Index, n.HostInterfaceIndex)
+		}
+
+		err = runInNetns(pid, func() error {
+			if err := v.initialize(n); err != nil {
+				return fmt.Errorf("initialize: %w", err)
+			}
+			link, err := netlink.LinkByName("eth0")
+			if err != nil {
+				return fmt.Errorf("container side: %w", err)
+			}
+			if link.Attrs().HardwareAddr.String() != n.MacAddress {
+				t.Errorf("expected MAC %s, got %s", n.MacAddress, link.Attrs().HardwareAddr)
+			}
+			if link.Attrs().Flags&unix.IFF_UP == 0 {
+				t.Errorf("container side is not up")
+			}
+			addrs, err := netlink.AddrList(link, netlink.FAMILY_V4)
+			if err != nil || len(addrs) != 1 || addrs[0].IPNet.String() != n.Address {
+				t.Errorf("expected address %s, got %v (%v)", n.Address, addrs, err)
+			}
+			return nil
+		})
+		if err != nil {
+			t.Error(err)
+		}
+
+		if err := v.detach(&n.Network); err != nil {
+			t.Errorf("detach: %v", err)
+		}
+		if err := v.destroy(&n.Network); err != nil {
+			t.Errorf("destroy: %v", err)
+		}
+		if _, err := netlink.LinkByName("testveth0"); err == nil {
+			t.Errorf("host side still exists after destroy")
+		}
+		// A second destroy is a no-op.
+		if err := v.destroy(&n.Network); err != nil {
+			t.Errorf("destroy: %v", err)
+		}
+
+		// Another veth which reused the name of the host side is left alone.
+		other := &netlink.Veth{LinkAttrs: netlink.LinkAttrs{Name: "testveth0"}, PeerName: "testveth1"}
+		if err := netlink.LinkAdd(other); err != nil {
+			t.Errorf("veth: %v", err)
+			return
+		}
+		if err := v.destroy(&n.Network); err != nil {
+			t.Errorf("destroy: %v", err)
+		}
+		if _, err := netlink.LinkByName("testveth0"); err != nil {
+			t.Errorf("another veth named after the host side was removed: %v", err)
+		}
+	})
+}
+
+func TestSetupRoute(t *testing.T) {
+	withNetns(t, func() {
+		link := &netlink.Veth{LinkAttrs: netlink.LinkAttrs{Name: "test0"}, PeerName: "test1"}
+		if err := netlink.LinkAdd(link); err != nil {
+			t.Errorf("veth: %v", err)
+			return
+		}
+		n := &network{Network: configs.Network{
+			Name:        "test0",
+			Address:     "10.0.0.2/24",
+			IPv6Address: "fd00::2/64",
+		}}
+		if err := configureInterface(link, n); err != nil {
+			t.Errorf("configure: %v", err)
+			return
+		}
+		config := &configs.Config{Routes: []*configs.Route{
+			{Destination: "10.1.0.0/16", Gateway: "10.0.0.1", Metric: 100},
+			{Destination: "10.2.0.0/16", InterfaceName: "test0"},
+			{Destination: "fd00:1::/64", Gateway: "fd00::1", Source: "fd00::2/64", InterfaceName: "test0", Metric: 10},
+		}}
+		if err := setupRoute(config); err != nil {
+			t.Errorf("setupRoute: %v", err)
+			return
+		}
+		for _, tc := range []struct {
+			dst      string
+			gw       string
+			scope    netlink.Scope
+			priority int
+		}{
+			{"10.1.0.0/16", "10.0.0.1", netlink.SCOPE_UNIVERSE, 100},
+			{"10.2.0.0/16", "", netlink.SCOPE_LINK, 0},
+			{"fd00:1::/64", "fd00::1", netlink.SCOPE_UNIVERSE, 10},
+		} {
+			_, dst, _ := net.ParseCIDR(tc.dst)
+			family := netlink.FAMILY_V4
+			if dst.IP.To4() == nil {
+				family = netlink.FAMILY_V6
+			}
+			routes, err := netlink.RouteListFiltered(family, &netlink.Route{Dst: dst}, netlink.RT_FILTER_DST)
+			if err != nil || len(routes) != 1 {
+				t.Errorf("route %s: got %v (%v)", tc.dst, routes, err)
+				continue
+			}
+			r := routes[0]
+			if (tc.gw == "" && r.Gw != nil) || (tc.gw != "" && !r.Gw.Equal(net.ParseIP(tc.gw))) {
+				t.Errorf("route %s: expected gateway %q, got %v", tc.dst, tc.gw, r.Gw)
+			}
+			if family == netlink.FAMILY_V4 && r.Scope != tc.scope {
+				t.Errorf("route %s: expected scope %v, got %v", tc.dst, tc.scope, r.Scope)
+			}
+			if tc.priority != 0 && r.Priority != tc.priority {
+				t.Errorf("route %s: expected metric %d, got %d", tc.dst, tc.priority, r.Priority)
+			}
+		}
+
+		err := setupRoute(&configs.Config{Routes: []*configs.Route{
+			{Destination: "10.3.0.0/16", Gateway: "fd00::1"},
+		}})
+		if err == nil || !strings.Contains(err.Error(), "10.3.0.0/16 via fd00::1") {
+			t.Errorf("expected an error mentioning the route, got %v", err)
+		}
+	})
+}
+
+// addParentLink adds a dummy link to be used as the parent of macvlan and
+// ipvlan links, falling back to a veth pair if dummy links aren't supported.
+func addParentLink(name string) (netlink.Link, error) {
+	var link netlink.Link = &netlink.Dummy{LinkAttrs: netlink.LinkAttrs{Name: name}}
+	err := netlink.LinkAdd(link)
+	if errors.Is(err, unix.EOPNOTSUPP) {
+		link = &netlink.Veth{LinkAttrs: netlink.LinkAttrs{Name: name}, PeerName: name + "p"}
+		err = netlink.LinkAdd(link)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return link, netlink.LinkSetUp(link)
+}
+
+func TestNetworkModes(t *testing.T) {
+	for _, m := range configs.NetworkModes["macvlan"] {
+		if _, ok := macvlanModes[m]; !ok {
+			t.Errorf("macvlan mode %q is not supported", m)
+		}
+	}
+	for _, m := range configs.NetworkModes["ipvlan"] {
+		if _, ok := ipvlanModes[m]; !ok {
+			t.Errorf("ipvlan mode %q is not supported", m)
+		}
+	}
+}
+
+func TestSubInterfaceStrategies(t *testing.T) {
+	for _, tc := range []struct {
+		typ, mode, linkType string
+	}{
+		{"macvlan", "bridge", "macvlan"},
+		{"macvlan", "private", "macvlan"},
+		{"macvlan", "vepa", "macvlan"},
+		{"ipvlan", "l2", "ipvlan"},
+		{"ipvlan", "l3", "ipvlan"},
+	} {
+		tc := tc
+		t.Run(tc.typ+"-"+tc.mode, func(t *testing.T) {
+			var skip string
+			withContainerNetns(t, func(pid int) {
+				skip = testSubInterfaceStrategy(t, pid, tc.typ, tc.mode, tc.linkType)
+			})
+			if skip != "" {
+				t.Skip(skip)
+			}
+		})
+	}
+}
+
+// testSubInterfaceStrategy runs the test on a locked thread and returns
+// the reason to skip it, if any, as t.Skip can't be called from there.
+func testSubInterfaceStrategy(t *testing.T, pid int, typ, mode, linkType string) string {
+	if _, err := addParentLink("parent0"); err != nil {
+		t.Errorf("parent: %v", err)
+		return ""
+	}
+
+	n := &network{Network: configs.Network{
+		Type:        typ,
+		Name:        "eth0",
+		Parent:      "parent0",
+		Mode:        mode,
+		Address:     "192.168.1.2/24",
+		Gateway:     "192.168.1.1",
+		IPv6Address: "fd00::2/64",
+		Mtu:         1400,
+	}}
+	strategy, err := getStrategy(typ)
+	if err != nil {
+		t.Error(err)
+		return ""
+	}
+	if err := strategy.create(n, pid); err != nil {
+		if errors.Is(err, unix.EOPNOTSUPP) {
+			return typ + " is not supported by the kernel"
+		}
+		t.Errorf("create: %v", err)
+		return ""
+	}
+	if _, err := netlink.LinkByName(n.TempVethPeerName); err == nil {
+		t.Errorf("%s was not moved to the container", n.TempVethPeerName)
+	}
+
+	err = runInNetns(pid, func() error {
+		if err := strategy.initialize(n); err != nil {
+			return fmt.Errorf("initialize: %w", err)
+		}
+		link, err := netlink.LinkByName("eth0")
+		if err != nil {
+			return fmt.Errorf("container side: %w", err)
+		}
+		if link.Type() != linkType {
+			t.Errorf("expected link type %s, got %s", linkType, link.Type())
+		}
+		if link.Attrs().MTU != 1400 {
+			t.Errorf("expected MTU 1400, got %d", link.Attrs().MTU)
+		}
+		addrs, err := netlink.AddrList(link, netlink.FAMILY_V4)
+		if err != nil || len(addrs) != 1 || addrs[0].IPNet.String() != n.Address {
+			t.Errorf("expected address %s, got %v (%v)", n.Address, addrs, err)
+		}
+		routes, err := netlink.RouteList(link, netlink.FAMILY_V4)
+		if err != nil {
+			return err
+		}
+		var found bool
+		for _, r := range routes {
+			if r.Dst == nil && r.Gw.Equal(net.ParseIP(n.Gateway)) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("default route via %s not found in %v", n.Gateway, routes)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Error(err)
+	}
+	if err := strategy.destroy(&n.Network); err != nil {
+		t.Errorf("destroy: %v", err)
+	}
+	return ""
+}
+
+func TestNetnsInterfaceStats(t *testing.T) {
+	withContainerNetns(t, func(pid int) {
+		// Set up the interfaces like CNI would, and generate some traffic.
+		err := runInNetns(pid, func() error {
+			if err := netlink.LinkAdd(&netlink.Veth{LinkAttrs: netlink.LinkAttrs{Name: "cni0"}, PeerName: "cni1"}); err != nil {
+				return err
+			}
+			lo, err := netlink.LinkByName("lo")
+			if err != nil {
+				return err
+			}
+			if err := netlink.LinkSetUp(lo); err != nil {
+				return err
+			}
+			conn, err := net.Dial("udp", "127.0.0.1:9")
+			if err != nil {
+				return err
+			}
+			defer conn.Close()
+			_, err = conn.Write([]byte("runc"))
+			return err
+		})
+		if err != nil {
+			t.Errorf("setup: %v", err)
+			return
+		}
+
+		stats, err := getNetnsInterfaceStats(pid)
+		if err != nil {
+			t.Errorf("getNetnsInterfaceStats: %v", err)
+			return
+		}
+		names := make(map[string]bool)
+		for _, s := range stats {
+			names[s.Name] = true
+			if s.Name == "lo" && (s.TxPackets == 0 || s.RxPackets == 0 || s.TxBytes == 0) {
+				t.Errorf("expected traffic on lo, got %+v", s)
+			}
+		}
+		for _, name := range []string{"lo", "cni0", "cni1"} {
+			if !names[name] {
+				t.Errorf("interface %s not found in %v", name, names)
+			}
+		}
+	})
+}
@@ -0,0 +1,282 @@
+package libcontainer
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/vishvananda/netlink"
+	"github.com/vishvananda/netlink/nl"
+	"golang.org/x/sys/unix"
+
+	"github.com/opencontainers/runc/libcontainer/configs"
+)
+
+const (
+	// defaultLatency is the default tbf latency, in microseconds.
+	defaultLatency = 50000
+	// shapingMtu is the maximum packet size the shaping is computed for.
+	shapingMtu = 1600
+)
+
+var (
+	egressHandle   = netlink.MakeHandle(1, 0)
+	egressClass    = netlink.MakeHandle(1, 1)
+	ingressHandle  = netlink.MakeHandle(0xffff, 0)
+	ingressClassID = netlink.MakeHandle(0xffff, 1)
+)
+
+// setupShaping sets the bandwidth limits of n on the container network
+// interface link, replacing the ones set before. If reset is true, the
+// limits which are no longer set in n are removed.
+func setupShaping(link netlink.Link, n *configs.Network, reset bool) error {
+	if n.Egress != nil {
+		if err := setEgressLimit(link, n.Egress, n.Qdisc); err != nil {
+			return fmt.Errorf("unable to set egress limit on %s: %w", n.Name, err)
+		}
+	} else if reset {
+		if err := deleteQdisc(link, netlink.HANDLE_ROOT, egressHandle); err != nil {
+			return fmt.Errorf("unable to remove egress limit on %s: %w", n.Name, err)
+		}
+	}
+	if n.Ingress != nil || reset {
+		// The ingress qdisc is recreated, as its policer filter can't be
+		// replaced in place.
+		if err := deleteQdisc(link, netlink.HANDLE_INGRESS, ingressHandle); err != nil {
+			return fmt.Errorf("unable to remove ingress limit on %s: %w", n.Name, err)
+		}
+	}
+	if n.Ingress != nil {
+		if err := setIngressLimit(link, n.Ingress); err != nil {
+			return fmt.Errorf("unable to set ingress limit on %s: %w", n.Name, err)
+		}
+	}
+	return nil
+}
+
+// deleteQdisc removes the qdisc of link with the given parent and handle,
+// if there is one. The qdiscs are listed first, as the kernel returns
+// EINVAL for a handle mismatch, which can't be told apart from other errors.
+func deleteQdisc(link netlink.Link, parent, handle uint32) error {
+	qdiscs, err := netlink.QdiscList(link)
+	if err != nil {
+		return err
+	}
+	for _, q := range qdiscs {
+		if q.Attrs().Parent == parent && q.Attrs().Handle == handle {
+			return netlink.QdiscDel(q)
+		}
+	}
+	return nil
+}
+
+func defaultBurst(rate uint64) uint32 {
+	return uint32(float64(rate)/netlink.Hz()) + shapingMtu
+}
+
+func setEgressLimit(link netlink.Link, b *configs.Bandwidth, qdisc string) error {
+	burst := b.Burst
+	if burst == 0 {
+		burst = defaultBurst(b.Rate)
+	}
+	attrs := netlink.QdiscAttrs{
+		LinkIndex: link.Attrs().Index,
+		Handle:    egressHandle,
+		Parent:    netlink.HANDLE_ROOT,
+	}
+	if qdisc == "" {
+		qdisc = "tbf"
+	}
+	// A qdisc can't be replaced by one of another kind with the same
+	// handle, so remove it first.
+	qdiscs, err := netlink.QdiscList(link)
+	if err != nil {
+		return err
+	}
+	for _, q := range qdiscs {
+		if q.Attrs().Parent == netlink.HANDLE_ROOT && q.Attrs().Handle == egressHandle && q.Type() != qdisc {
+			if err := netlink.QdiscDel(q); err != nil {
+				return err
+			}
+		}
+	}
+	switch qdisc {
+	case "tbf":
+		latency := b.Latency
+		if latency == 0 {
+			latency = defaultLatency
+		}
+		return netlink.QdiscReplace(&netlink.Tbf{
+			QdiscAttrs: attrs,
+			Rate:       b.Rate,
+			Buffer:     uint32(netlink.Xmittime(b.Rate, burst)),
+			Limit:      uint32(float64(b.Rate)*float64(latency)/1e6) + burst,
+		})
+	case "htb":
+		htb := netlink.NewHtb(attrs)
+		_, minor := netlink.MajorMinor(egressClass)
+		htb.Defcls = uint32(minor)
+		if err := netlink.QdiscReplace(htb); err != nil {
+			return err
+		}
+		return netlink.ClassReplace(netlink.NewHtbClass(netlink.ClassAttrs{
+			LinkIndex: link.Attrs().Index,
+			Handle:    egressClass,
+			Parent:    egressHandle,
+		}, netlink.HtbClassAttrs{
+			// The rate is in bits per second.
+			Rate:   b.Rate * 8,
+			Buffer: burst,
+		}))
+	}
+	return fmt.Errorf("unknown qdisc %q", qdisc)
+}
+
+func ingressQdisc(link netlink.Link) *netlink.Ingress {
+	return &netlink.Ingress{QdiscAttrs: netlink.QdiscAttrs{
+		LinkIndex: link.Attrs().Index,
+		Handle:    ingressHandle,
+		Parent:    netlink.HANDLE_INGRESS,
+	}}
+}
+
+// setIngressLimit adds an ingress qdisc with a u32 filter matching all the
+// packets, and policing them at the given bandwidth, just like
+//
+//	tc filter add dev $DEV parent ffff: protocol all u32 match u32 0 0 \
+//		police rate $RATE burst $BURST drop flowid :1
+func setIngressLimit(link netlink.Link, b *configs.Bandwidth) error {
+	if err := netlink.QdiscAdd(ingressQdisc(link)); err != nil {
+		return err
+	}
+	burst := b.Burst
+	if burst == 0 {
+		burst = defaultBurst(b.Rate)
+	}
+	var rtab [256]uint32
+	police := nl.TcPolice{
+		Action: int32(netlink.TC_POLICE_SHOT),
+		Mtu:    shapingMtu,
+	}
+	police.Rate.Rate = uint32(b.Rate)
+	if netlink.CalcRtable(&police.Rate, rtab[:], -1, police.Mtu, nl.LINKLAYER_ETHERNET) < 0 {
+		return errors.New("unable to calculate the rate table")
+	}
+	police.Burst = uint32(netlink.Xmittime(b.Rate, burst))
+
+	// The vendored netlink does not support policing with u32 filters,
+	// so the request is built here.
+	req := nl.NewNetlinkRequest(unix.RTM_NEWTFILTER, unix.NLM_F_CREATE|unix.NLM_F_EXCL|unix.NLM_F_ACK)
+	req.AddData(&nl.TcMsg{
+		Family:  nl.FAMILY_ALL,
+		Ifindex: int32(link.Attrs().Index),
+		Parent:  ingressHandle,
+		Info:    netlink.MakeHandle(1, nl.Swap16(unix.ETH_P_ALL)),
+	})
+	req.AddData(nl.NewRtAttr(nl.TCA_KIND, nl.ZeroTerminated("u32")))
+	options := nl.NewRtAttr(nl.TCA_OPTIONS, nil)
+	sel := nl.TcU32Sel{Flags: nl.TC_U32_TERMINAL, Nkeys: 1, Keys: []nl.TcU32Key{{}}}
+	options.AddRtAttr(nl.TCA_U32_SEL, sel.Serialize())
+	options.AddRtAttr(nl.TCA_U32_CLASSID, nl.Uint32Attr(ingressClassID))
+	p := options.AddRtAttr(nl.TCA_U32_POLICE, nil)
+	p.AddRtAttr(nl.TCA_POLICE_TBF, police.Serialize())
+	p.AddRtAttr(nl.TCA_POLICE_RATE, netlink.SerializeRtab(rtab))
+	req.AddData(options)
+	_, err := req.Execute(unix.NETLINK_ROUTE, 0)
+	return err
+}
+
+// qdiscDrops are the numbers of packets dropped by the qdiscs of a network
+// interface.
+type qdiscDrops struct {
+	egress, ingress uint64
+}
+
+// getQdiscDrops returns the numbers of packets dropped by the egress and
+// ingress qdiscs of the network interfaces, by interface index.
+func getQdiscDrops() (map[int]*qdiscDrops, error) {
+	// The vendored netlink does not report qdisc statistics.
+	req := nl.NewNetlinkRequest(unix.RTM_GETQDISC, unix.NLM_F_DUMP)
+	req.AddData(&nl.TcMsg{Family: nl.FAMILY_ALL})
+	msgs, err := req.Execute(unix.NETLINK_ROUTE, unix.RTM_NEWQDISC)
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[int]*qdiscDrops)
+	for _, m := range msgs {
+		msg := nl.DeserializeTcMsg(m)
+		d := res[int(msg.Ifindex)]
+		if d == nil {
+			d = &qdiscDrops{}
+			res[int(msg.Ifindex)] = d
+		}
+		var drops *uint64
+		switch msg.Parent {
+		case netlink.HANDLE_ROOT:
+			drops = &d.egress
+		case netlink.HANDLE_INGRESS:
+			drops = &d.ingress
+		default:
+			continue
+		}
+		attrs, err := nl.ParseRouteAttr(m[msg.Len():])
+		if err != nil {
+			return nil, err
+		}
+		for _, attr := range attrs {
+			if attr.Attr.Type != nl.TCA_STATS2 {
+				continue
+			}
+			stats, err := nl.ParseRouteAttr(attr.Value)
+			if err != nil {
+				return nil, err
+			}
+			for _, s := range stats {
+				// struct gnet_stats_queue { qlen, backlog, drops, requeues, overlimits }
+				if s.Attr.Type == nl.TCA_STATS_QUEUE && len(s.Value) >= 12 {
+					*drops = uint64(nl.NativeEndian().Uint32(s.Value[8:12]))
+				}
+			}
+		}
+	}
+	return res, nil
+}
+
+// hasShaping reports whether bandwidth limits are set on n.
+func hasShaping(n *configs.Network) bool {
+	return n.Egress != nil || n.Ingress != nil
+}
+
+// updateShaping changes the bandwidth limits of the networks of the
+// running container with the given init pid from the old ones to the ones
+// set in networks.
+func updateShaping(pid int, old, networks []*configs.Network) error {
+	var changed []*configs.Network
+	for _, n := range networks {
+		for _, o := range old {
+			if o.Name != n.Name || o.Type != n.Type {
+				continue
+			}
+			if !reflect.DeepEqual(o.Egress, n.Egress) ||
+				!reflect.DeepEqual(o.Ingress, n.Ingress) ||
+				o.Qdisc != n.Qdisc {
+				changed = append(changed, n)
+			}
+		}
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+	return runInNetns(pid, func() error {
+		for _, n := range changed {
+			link, err := netlink.LinkByName(n.Name)
+			if err != nil {
+				return err
+			}
+			if err := setupShaping(link, n, true); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,181 @@
+package libcontainer
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/vishvananda/netlink"
+	"golang.org/x/sys/unix"
+
+	"github.com/opencontainers/runc/libcontainer/configs"
+)
+
+// qdiscKinds returns the kinds of the root and ingress qdiscs of link.
+func qdiscKinds(link netlink.Link) (root, ingress string, err error) {
+	qdiscs, err := netlink.QdiscList(link)
+	if err != nil {
+		return "", "", err
+	}
+	for _, q := range qdiscs {
+		switch q.Attrs().Parent {
+		case netlink.HANDLE_ROOT:
+			root = q.Type()
+		case netlink.HANDLE_INGRESS:
+			ingress = q.Type()
+		}
+	}
+	return root, ingress, nil
+}
+
+func TestSetupShapingEgress(t *testing.T) {
+	withNetns(t, func() {
+		link := &netlink.Veth{LinkAttrs: netlink.LinkAttrs{Name: "test0"}, PeerName: "test1"}
+		if err := netlink.LinkAdd(link); err != nil {
+			t.Errorf("veth: %v", err)
+			return
+		}
+		n := &configs.Network{
+			Type:   "veth",
+			Name:   "test0",
+			Egress: &configs.Bandwidth{Rate: 1 << 20, Burst: 1 << 15, Latency: 10000},
+		}
+		if err := setupShaping(link, n, false); err != nil {
+			t.Errorf("setupShaping: %v", err)
+			return
+		}
+		qdiscs, err := netlink.QdiscList(link)
+		if err != nil {
+			t.Error(err)
+		}
+		var found bool
+		for _, q := range qdiscs {
+			if tbf, ok := q.(*netlink.Tbf); ok && tbf.Parent == netlink.HANDLE_ROOT {
+				found = true
+				if tbf.Rate != n.Egress.Rate {
+					t.Errorf("expected tbf rate %d, got %d", n.Egress.Rate, tbf.Rate)
+				}
+			}
+		}
+		if !found {
+			t.Errorf("tbf qdisc not found in %v", qdiscs)
+		}
+		if _, err := getQdiscDrops(); err != nil {
+			t.Errorf("getQdiscDrops: %v", err)
+		}
+
+		// Change the rate and qdisc.
+		n.Egress = &configs.Bandwidth{Rate: 1 << 21}
+		n.Qdisc = "htb"
+		if err := setupShaping(link, n, true); err != nil {
+			t.Errorf("setupShaping: %v", err)
+			return
+		}
+		root, _, err := qdiscKinds(link)
+		if err != nil || root != "htb" {
+			t.Errorf("expected htb qdisc, got %q (%v)", root, err)
+		}
+		classes, err := netlink.ClassList(link, egressHandle)
+		if err != nil || len(classes) != 1 {
+			t.Errorf("expected a htb class, got %v (%v)", classes, err)
+		}
+
+		// Remove the limit.
+		n.Egress = nil
+		n.Qdisc = ""
+		if err := setupShaping(link, n, true); err != nil {
+			t.Errorf("setupShaping: %v", err)
+			return
+		}
+		root, _, err = qdiscKinds(link)
+		if err != nil || root == "htb" {
+			t.Errorf("expected no egress limit, got %q (%v)", root, err)
+		}
+	})
+}
+
+func TestSetupShapingIngress(t *testing.T) {
+	var skip string
+	withNetns(t, func() {
+		link := &netlink.Veth{LinkAttrs: netlink.LinkAttrs{Name: "test0"}, PeerName: "test1"}
+		if err := netlink.LinkAdd(link); err != nil {
+			t.Errorf("veth: %v", err)
+			return
+		}
+		n := &configs.Network{
+			Type:    "veth",
+			Name:    "test0",
+			Ingress: &configs.Bandwidth{Rate: 1 << 20},
+		}
+		if err := setupShaping(link, n, false); err != nil {
+			// The kernel returns ENOENT if the police action is missing.
+			if errors.Is(err, unix.ENOENT) || errors.Is(err, unix.EOPNOTSUPP) {
+				skip = "ingress policing is not supported by the kernel: " + err.Error()
+				return
+			}
+			t.Errorf("setupShaping: %v", err)
+			return
+		}
+		_, ingress, err := qdiscKinds(link)
+		if err != nil || ingress != "ingress" {
+			t.Errorf("expected ingress qdisc, got %q (%v)", ingress, err)
+		}
+		filters, err := netlink.FilterList(link, ingressHandle)
+		if err != nil || len(filters) == 0 || filters[0].Type() != "u32" {
+			t.Errorf("expected a u32 ingress filter, got %v (%v)", filters, err)
+		}
+
+		// Changing the rate replaces the filter.
+		n.Ingress = &configs.Bandwidth{Rate: 1 << 21, Burst: 1 << 16}
+		if err := setupShaping(link, n, true); err != nil {
+			t.Errorf("setupShaping: %v", err)
+		}
+		n.Ingress = nil
+		if err := setupShaping(link, n, true); err != nil {
+			t.Errorf("setupShaping: %v", err)
+		}
+		_, ingress, err = qdiscKinds(link)
+		if err != nil || ingress != "" {
+			t.Errorf("expected no ingress limit, got %q (%v)", ingress, err)
+		}
+	})
+	if skip != "" {
+		t.Skip(skip)
+	}
+}
+
+func TestUpdateShaping(t *testing.T) {
+	withContainerNetns(t, func(pid int) {
+		err := runInNetns(pid, func() error {
+			return netlink.LinkAdd(&netlink.Veth{LinkAttrs: netlink.LinkAttrs{Name: "test0"}, PeerName: "test1"})
+		})
+		if err != nil {
+			t.Errorf("veth: %v", err)
+			return
+		}
+
+		old := []*configs.Network{{Type: "veth", Name: "test0"}}
+		networks := []*configs.Network{{Type: "veth", Name: "test0", Egress: &configs.Bandwidth{Rate: 1 << 20}}}
+		if err := updateShaping(pid, old, networks); err != nil {
+			t.Errorf("updateShaping: %v", err)
+			return
+		}
+		// The test namespace is left untouched.
+		if _, err := netlink.LinkByName("test0"); err == nil {
+			t.Errorf("test0 was created in the wrong namespace")
+		}
+		err = runInNetns(pid, func() error {
+			link, err := netlink.LinkByName("test0")
+			if err != nil {
+				return err
+			}
+			root, _, err := qdiscKinds(link)
+			if err == nil && root != "tbf" {
+				t.Errorf("expected tbf qdisc, got %q", root)
+			}
+			return err
+		})
+		if err != nil {
+			t.Error(err)
+		}
+	})
+}
@@ -17,6 +17,15 @@ const (
 	CriticalPressure
 )
 
+var pressureLevelNames = []string{"low", "medium", "critical"}
+
+func (l PressureLevel) String() string {
+	if l > CriticalPressure {
+		return fmt.Sprintf("PressureLevel(%d)", uint(l))
+	}
+	return pressureLevelNames[l]
+}
+
 func registerMemoryEvent(cgDir string, evName string, arg string) (<-chan struct{}, error) {
 	evFile, err := os.Open(filepath.Join(cgDir, evName))
 	if err != nil {
@@ -79,6 +88,5 @@ func notifyMemoryPressure(dir string, level PressureLevel) (<-chan struct{}, err
 		return nil, fmt.Errorf("invalid pressure level %d", level)
 	}
 
-	levelStr := []string{"low", "medium", "critical"}[level]
-	return registerMemoryEvent(dir, "memory.pressure_level", levelStr)
+	return registerMemoryEvent(dir, "memory.pressure_level", level.String())
 }
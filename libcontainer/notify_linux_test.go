@@ -115,3 +115,30 @@ func TestNotifyMemoryPressure(t *testing.T) {
 		testMemoryNotification(t, "memory.pressure_level", f, arg)
 	}
 }
+
+func TestPSITrigger(t *testing.T) {
+	for level, want := range map[PressureLevel]string{
+		LowPressure:      "some 70000 1000000",
+		MediumPressure:   "some 150000 1000000",
+		CriticalPressure: "full 150000 1000000",
+	} {
+		trigger := psiTriggers[level]
+		if err := trigger.validate(); err != nil {
+			t.Errorf("%s: unexpected error: %v", level, err)
+		}
+		if got := trigger.String(); got != want {
+			t.Errorf("%s: expected trigger %q, got %q", level, want, got)
+		}
+	}
+
+	for _, trigger := range []PSITrigger{
+		{Threshold: 100 * time.Millisecond, Window: 100 * time.Millisecond},
+		{Threshold: 100 * time.Millisecond, Window: 20 * time.Second},
+		{Threshold: 2 * time.Second, Window: time.Second},
+		{Window: time.Second},
+	} {
+		if err := trigger.validate(); err == nil {
+			t.Errorf("%+v: expected error, got nil", trigger)
+		}
+	}
+}
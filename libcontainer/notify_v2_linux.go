@@ -1,8 +1,11 @@
 package libcontainer
 
 import (
+	"errors"
 	"fmt"
+	"os"
 	"path/filepath"
+	"time"
 	"unsafe"
 
 	"github.com/opencontainers/runc/libcontainer/cgroups/fscommon"
@@ -78,3 +81,101 @@ func registerMemoryEventV2(cgDir, evName, cgEvName string) (<-chan struct{}, err
 func notifyOnOOMV2(path string) (<-chan struct{}, error) {
 	return registerMemoryEventV2(path, "memory.events", "cgroup.events")
 }
+
+// PSITrigger describes a cgroup v2 PSI (Pressure Stall Information) trigger.
+// A notification is sent when the tasks of the cgroup were stalled for more
+// than Threshold during any Window period.
+// See https://www.kernel.org/doc/html/latest/accounting/psi.html#userspace-monitors.
+type PSITrigger struct {
+	// Full selects the "full" stall time (all tasks stalled at the same
+	// time) rather than the "some" one (at least one task stalled).
+	Full bool
+	// Threshold is the stall time that triggers the notification.
+	Threshold time.Duration
+	// Window is the time window to track the stall time over. The kernel
+	// only accepts windows between 500ms and 10s.
+	Window time.Duration
+}
+
+// psiTriggers maps the memory pressure levels to the PSI triggers used to
+// emulate them on cgroup v2.
+var psiTriggers = map[PressureLevel]PSITrigger{
+	LowPressure:      {Threshold: 70 * time.Millisecond, Window: time.Second},
+	MediumPressure:   {Threshold: 150 * time.Millisecond, Window: time.Second},
+	CriticalPressure: {Full: true, Threshold: 150 * time.Millisecond, Window: time.Second},
+}
+
+func (t PSITrigger) validate() error {
+	if t.Window < 500*time.Millisecond || t.Window > 10*time.Second {
+		return fmt.Errorf("invalid PSI trigger window %s: must be between 500ms and 10s", t.Window)
+	}
+	if t.Threshold <= 0 || t.Threshold > t.Window {
+		return fmt.Errorf("invalid PSI trigger threshold %s: must be positive and not exceed the window", t.Threshold)
+	}
+	return nil
+}
+
+// String returns the trigger in the format expected by the pressure files,
+// e.g. "some 150000 1000000".
+func (t PSITrigger) String() string {
+	kind := "some"
+	if t.Full {
+		kind = "full"
+	}
+	return fmt.Sprintf("%s %d %d", kind, t.Threshold.Microseconds(), t.Window.Microseconds())
+}
+
+// registerPSITrigger installs the given trigger on a cgroup v2 pressure file
+// and returns a channel that receives a value every time the trigger fires.
+// The channel is closed once the cgroup is removed.
+func registerPSITrigger(cgDir, file string, trigger PSITrigger) (<-chan struct{}, error) {
+	if err := trigger.validate(); err != nil {
+		return nil, err
+	}
+	path := filepath.Join(cgDir, file)
+	fd, err := unix.Open(path, unix.O_RDWR|unix.O_NONBLOCK|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return nil, &os.PathError{Op: "open", Path: path, Err: err}
+	}
+	// The trigger has to be written in one go, NUL-terminated.
+	if _, err := unix.Write(fd, append([]byte(trigger.String()), 0)); err != nil {
+		unix.Close(fd)
+		return nil, fmt.Errorf("unable to set PSI trigger %q on %s: %w", trigger, path, err)
+	}
+	ch := make(chan struct{})
+	go func() {
+		defer func() {
+			unix.Close(fd)
+			close(ch)
+		}()
+
+		fds := []unix.PollFd{{Fd: int32(fd), Events: unix.POLLPRI}}
+		for {
+			if _, err := unix.Poll(fds, -1); err != nil {
+				if errors.Is(err, unix.EINTR) {
+					continue
+				}
+				logrus.Warnf("unable to poll PSI trigger on %s: %v", path, err)
+				return
+			}
+			// The kernel reports POLLERR once the cgroup is gone.
+			if fds[0].Revents&unix.POLLERR != 0 {
+				return
+			}
+			if fds[0].Revents&unix.POLLPRI != 0 {
+				ch <- struct{}{}
+			}
+		}
+	}()
+	return ch, nil
+}
+
+// notifyMemoryPressureV2 emulates the cgroup v1 memory pressure levels
+// on top of PSI triggers.
+func notifyMemoryPressureV2(path string, level PressureLevel) (<-chan struct{}, error) {
+	trigger, ok := psiTriggers[level]
+	if !ok {
+		return nil, fmt.Errorf("invalid pressure level %d", level)
+	}
+	return registerPSITrigger(path, "memory.pressure", trigger)
+}
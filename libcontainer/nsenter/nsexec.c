@@ -94,6 +94,14 @@ struct nlconfig_t {
 	/* Mount sources opened outside the container userns. */
 	char *mountsources;
 	size_t mountsources_len;
+
+	/* Time namespace offsets. */
+	char *timensoffset;
+	size_t timensoffset_len;
+
+	/* Initial CPU affinity (a cpu_set_t). */
+	char *cpuaffinity;
+	size_t cpuaffinity_len;
 };
 
 /*
@@ -127,6 +135,8 @@ static int loglevel = DEBUG;
 #define UIDMAPPATH_ATTR		27288
 #define GIDMAPPATH_ATTR		27289
 #define MOUNT_SOURCES_ATTR	27290
+#define TIMENS_OFFSET_ATTR	27291
+#define CPU_AFFINITY_ATTR	27292
 
 /*
  * Use the raw syscall for versions of glibc which don't include a function for
@@ -376,6 +386,45 @@ static void update_oom_score_adj(char *data, size_t len)
 		bail("failed to update /proc/self/oom_score_adj");
 }
 
+static void update_timens_offsets(char *data, size_t len)
+{
+	if (data == NULL || len == 0)
+		return;
+
+	write_log(DEBUG, "update /proc/self/timens_offsets to '%s'", data);
+	if (write_file(data, len, "/proc/self/timens_offsets") < 0)
+		bail("failed to update /proc/self/timens_offsets");
+}
+
+static void update_cpu_affinity(char *data, size_t len)
+{
+	cpu_set_t *set = (cpu_set_t *)data;
+	int ncpus = len * 8, cpu, last;
+	char list[64] = "";
+	size_t n = 0;
+
+	if (data == NULL || len == 0)
+		return;
+
+	if (sched_setaffinity(0, len, set) < 0)
+		bail("failed to set initial cpu affinity");
+
+	/* Log the resulting affinity as a CPU list, for debugging purposes. */
+	if (sched_getaffinity(0, len, set) < 0)
+		bail("failed to get cpu affinity");
+	for (cpu = 0; cpu < ncpus && n < sizeof(list); cpu++) {
+		if (!CPU_ISSET_S(cpu, len, set))
+			continue;
+		for (last = cpu; last + 1 < ncpus && CPU_ISSET_S(last + 1, len, set); last++) ;
+		if (last == cpu)
+			n += snprintf(list + n, sizeof(list) - n, "%s%d", n ? "," : "", cpu);
+		else
+			n += snprintf(list + n, sizeof(list) - n, "%s%d-%d", n ? "," : "", cpu, last);
+		cpu = last;
+	}
+	write_log(DEBUG, "set initial cpu affinity to %s", list);
+}
+
 /* A dummy function that just jumps to the given jumpval. */
 static int child_func(void *arg) __attribute__((noinline));
 static int child_func(void *arg)
@@ -464,6 +513,8 @@ static int nsflag(char *name)
 		return CLONE_NEWUSER;
 	else if (!strcmp(name, "uts"))
 		return CLONE_NEWUTS;
+	else if (!strcmp(name, "time"))
+		return CLONE_NEWTIME;
 
 	/* If we don't recognise a name, fallback to 0. */
 	return 0;
@@ -554,6 +605,15 @@ static void nl_parse(int fd, struct nlconfig_t *config)
 			config->mountsources = current;
 			config->mountsources_len = payload_len;
 			break;
+		case TIMENS_OFFSET_ATTR:
+			config->timensoffset = current;
+			config->timensoffset_len = payload_len;
+			break;
+		case CPU_AFFINITY_ATTR:
+			config->cpuaffinity = current;
+			/* Not a string, so drop the trailing NUL byte. */
+			config->cpuaffinity_len = payload_len - 1;
+			break;
 		default:
 			bail("unknown netlink message type %d", nlattr->nla_type);
 		}
@@ -876,6 +936,14 @@ void nsexec(void)
 	/* Parse all of the netlink configuration. */
 	nl_parse(pipenum, &config);
 
+	/*
+	 * Set the initial CPU affinity. The parent has started us with it
+	 * already, but it is reset by the kernel if we were started directly
+	 * in the container cgroup (CLONE_INTO_CGROUP). Our children inherit
+	 * it on fork(2).
+	 */
+	update_cpu_affinity(config.cpuaffinity, config.cpuaffinity_len);
+
 	/* Set oom_score_adj. This has to be done before !dumpable because
 	 * /proc/self/oom_score_adj is not writeable unless you're an privileged
 	 * user (if !dumpable is set). All children inherit their parent's
@@ -1228,6 +1296,14 @@ void nsexec(void)
 			if (unshare(config.cloneflags & ~CLONE_NEWCGROUP) < 0)
 				bail("failed to unshare remaining namespaces (except cgroupns)");
 
+			/*
+			 * The time namespace offsets can only be written before any
+			 * process has been created in the new namespace. unshare(2) of
+			 * CLONE_NEWTIME only affects our children, so we have to set the
+			 * offsets now, before spawning stage-2.
+			 */
+			update_timens_offsets(config.timensoffset, config.timensoffset_len);
+
 			/* Ask our parent to send the mount sources fds. */
 			if (config.mountsources) {
 				s = SYNC_MOUNTSOURCES_PLS;
@@ -0,0 +1,85 @@
+package libcontainer
+
+import (
+	"errors"
+	"os"
+	"strconv"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/sys/unix"
+
+	"github.com/opencontainers/runc/libcontainer/system"
+)
+
+// openPidfd returns a pidfd (see pidfd_open(2)) referring to the process
+// with the given pid. Unlike a pid, a pidfd can not be reused to refer to
+// another process once the original one has exited, so it is used to signal
+// the process and to find out whether it has exited.
+//
+// Since pidfd_open(2) is only available since Linux 5.3, nil is returned if
+// a pidfd can not be obtained, and the callers fall back to using the pid.
+func openPidfd(pid int) *os.File {
+	fd, err := unix.PidfdOpen(pid, 0)
+	if err != nil {
+		if !errors.Is(err, unix.ENOSYS) {
+			logrus.Debugf("unable to open pidfd for pid %d: %v", pid, err)
+		}
+		return nil
+	}
+	return os.NewFile(uintptr(fd), "pidfd:"+strconv.Itoa(pid))
+}
+
+// openPidfdStartTime is like openPidfd, but for a process which is not a
+// child, whose pid may have been reused since it was recorded: the pidfd is
+// only returned if the process has the given start time, and ESRCH is
+// returned otherwise. The caller must close the pidfd.
+func openPidfdStartTime(pid int, startTime uint64) (*os.File, error) {
+	pidfd := openPidfd(pid)
+	if pidfd == nil {
+		return nil, nil
+	}
+	// The pidfd refers to the process having the pid when it was opened,
+	// so if that process is still there now, it is the right one.
+	stat, err := system.Stat(pid)
+	if err != nil || stat.StartTime != startTime {
+		_ = pidfd.Close()
+		return nil, unix.ESRCH
+	}
+	return pidfd, nil
+}
+
+// signalPidfd sends the signal s to the process referred to by pidfd, or
+// to the process with the given pid if pidfd is nil.
+func signalPidfd(pidfd *os.File, pid int, s os.Signal) error {
+	sig, ok := s.(unix.Signal)
+	if !ok {
+		return errors.New("os: unsupported signal type")
+	}
+	if pidfd == nil {
+		return unix.Kill(pid, sig)
+	}
+	err := system.PidfdSendSignal(int(pidfd.Fd()), sig)
+	// Return a bare errno, like unix.Kill does.
+	var errno unix.Errno
+	if errors.As(err, &errno) {
+		return errno
+	}
+	return err
+}
+
+// pidfdExited reports whether the process referred to by pidfd has exited.
+// It always returns false if pidfd is nil.
+func pidfdExited(pidfd *os.File) bool {
+	if pidfd == nil {
+		return false
+	}
+	fds := []unix.PollFd{{Fd: int32(pidfd.Fd()), Events: unix.POLLIN}}
+	for {
+		n, err := unix.Poll(fds, 0)
+		if errors.Is(err, unix.EINTR) {
+			continue
+		}
+		// A pidfd becomes readable once the process has exited.
+		return err == nil && n == 1 && fds[0].Revents&unix.POLLIN != 0
+	}
+}
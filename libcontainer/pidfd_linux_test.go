@@ -0,0 +1,70 @@
+package libcontainer
+
+import (
+	"errors"
+	"os/exec"
+	"testing"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/opencontainers/runc/libcontainer/system"
+)
+
+func TestPidfdSignalAndExit(t *testing.T) {
+	cmd := exec.Command("sleep", "100")
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	pidfd := openPidfd(cmd.Process.Pid)
+	if pidfd == nil {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		t.Skip("pidfd_open(2) not supported")
+	}
+	defer pidfd.Close()
+
+	if pidfdExited(pidfd) {
+		t.Fatal("expected the process to be running")
+	}
+	if err := signalPidfd(pidfd, cmd.Process.Pid, unix.SIGKILL); err != nil {
+		t.Fatal(err)
+	}
+	_ = cmd.Wait()
+	if !pidfdExited(pidfd) {
+		t.Fatal("expected the process to have exited")
+	}
+	// The pid may have been reused by now, but the pidfd still refers to
+	// the original (reaped) process, so signalling it must fail.
+	if err := signalPidfd(pidfd, cmd.Process.Pid, unix.Signal(0)); !errors.Is(err, unix.ESRCH) {
+		t.Fatalf("expected ESRCH, got %v", err)
+	}
+}
+
+func TestOpenPidfdStartTime(t *testing.T) {
+	cmd := exec.Command("sleep", "100")
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	}()
+	pid := cmd.Process.Pid
+	stat, err := system.Stat(pid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pidfd, err := openPidfdStartTime(pid, stat.StartTime)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pidfd == nil {
+		t.Skip("pidfd_open(2) not supported")
+	}
+	_ = pidfd.Close()
+
+	// A process with another start time has reused the pid.
+	if _, err := openPidfdStartTime(pid, stat.StartTime+1); !errors.Is(err, unix.ESRCH) {
+		t.Fatalf("expected ESRCH, got %v", err)
+	}
+}
@@ -64,6 +64,10 @@ type Process struct {
 	// Label specifies the label to apply to the process.  It is commonly used by selinux
 	Label string
 
+	// Landlock specifies the Landlock ruleset to apply to the process, overriding
+	// the one from the container config. It is enforced right before the process is execed
+	Landlock *configs.Landlock
+
 	// NoNewPrivileges controls whether processes can gain additional privileges.
 	NoNewPrivileges *bool
 
@@ -71,6 +75,19 @@ type Process struct {
 	// If Rlimits are not set, the container will inherit rlimits from the parent process
 	Rlimits []configs.Rlimit
 
+	// Scheduler specifies the scheduling policy and attributes of the process.
+	// If Scheduler is not set, the one from the container config is used
+	Scheduler *configs.Scheduler
+
+	// IOPriority specifies the I/O scheduling class and priority of the process.
+	// If IOPriority is not set, the one from the container config is used
+	IOPriority *configs.IOPriority
+
+	// CPUAffinity specifies the initial and final CPU affinity of the process.
+	// If CPUAffinity is not set, the ExecCPUAffinity from the container config
+	// is used for processes other than the container init
+	CPUAffinity *configs.CPUAffinity
+
 	// ConsoleSocket provides the masterfd console.
 	ConsoleSocket *os.File
 
@@ -0,0 +1,16 @@
+//go:build !go1.20
+// +build !go1.20
+
+package libcontainer
+
+import "os/exec"
+
+// setCgroupFD is a no-op, as CLONE_INTO_CGROUP is only supported by
+// os/exec since Go 1.20.
+func setCgroupFD(_ *exec.Cmd, _ int) bool {
+	return false
+}
+
+func unsetCgroupFD(_ *exec.Cmd) bool {
+	return false
+}
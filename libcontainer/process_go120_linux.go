@@ -0,0 +1,30 @@
+//go:build go1.20
+// +build go1.20
+
+package libcontainer
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// setCgroupFD makes cmd start directly in the cgroup referred to by fd,
+// using clone3(2) with CLONE_INTO_CGROUP.
+func setCgroupFD(cmd *exec.Cmd, fd int) bool {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.UseCgroupFD = true
+	cmd.SysProcAttr.CgroupFD = fd
+	return true
+}
+
+// unsetCgroupFD undoes setCgroupFD, and reports whether it was set.
+func unsetCgroupFD(cmd *exec.Cmd) bool {
+	if cmd.SysProcAttr == nil || !cmd.SysProcAttr.UseCgroupFD {
+		return false
+	}
+	// CgroupFD is never used when UseCgroupFD is unset.
+	cmd.SysProcAttr.UseCgroupFD = false
+	return true
+}
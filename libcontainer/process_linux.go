@@ -42,6 +42,10 @@ type parentProcess interface {
 
 	signal(os.Signal) error
 
+	// pidfd returns a pidfd referring to the process, or nil if it is
+	// not available.
+	pidfd() *os.File
+
 	externalDescriptors() []string
 
 	setExternalDescriptors(fds []string)
@@ -67,6 +71,7 @@ type setnsProcess struct {
 	process         *Process
 	bootstrapData   io.Reader
 	initProcessPid  int
+	pidfdFile       *os.File
 }
 
 func (p *setnsProcess) startTime() (uint64, error) {
@@ -75,18 +80,43 @@ func (p *setnsProcess) startTime() (uint64, error) {
 }
 
 func (p *setnsProcess) signal(sig os.Signal) error {
-	s, ok := sig.(unix.Signal)
-	if !ok {
-		return errors.New("os: unsupported signal type")
+	return signalPidfd(p.pidfdFile, p.pid(), sig)
+}
+
+func (p *setnsProcess) pidfd() *os.File {
+	return p.pidfdFile
+}
+
+// cgroupDir returns the cgroup v2 directory the process is to be started
+// in, which is the container cgroup, or its sub-cgroup if one is requested
+// by Process.SubCgroupPaths. An empty string is returned on cgroup v1, or
+// if the sub-cgroup does not exist, in which case the process is moved to
+// its cgroups after it is started.
+func (p *setnsProcess) cgroupDir() string {
+	dir, ok := p.cgroupPaths[""]
+	if !ok || !cgroups.IsCgroup2UnifiedMode() {
+		return ""
 	}
-	return unix.Kill(p.pid(), s)
+	if len(p.process.SubCgroupPaths) > 0 {
+		if _, ok := p.process.SubCgroupPaths[""]; !ok {
+			// Per-controller sub-cgroups are cgroup v1 only.
+			return ""
+		}
+		// Unlike the container cgroup, the sub-cgroup is not created by
+		// runc; let WriteCgroupProc report a proper error if it is missing.
+		if _, err := os.Stat(dir); err != nil {
+			return ""
+		}
+	}
+	return dir
 }
 
 func (p *setnsProcess) start() (retErr error) {
 	defer p.messageSockPair.parent.Close()
 	// get the "before" value of oom kill count
 	oom, _ := p.manager.OOMKillCount()
-	err := p.cmd.Start()
+	var err error
+	p.cmd, err = startInCgroup(p.cmd, p.cgroupDir())
 	// close the write-side of the pipes (controlled by child)
 	p.messageSockPair.child.Close()
 	p.logFilePair.child.Close()
@@ -156,6 +186,9 @@ func (p *setnsProcess) start() (retErr error) {
 			}
 		}
 	}
+	if err := setFinalCPUAffinity(p.config.CPUAffinity, p.pid()); err != nil {
+		return err
+	}
 	// set rlimits, this has to be done here because we lose permissions
 	// to raise the limits once we enter a user-namespace
 	if err := setupRlimits(p.config.Rlimits, p.pid()); err != nil {
@@ -257,6 +290,7 @@ func (p *setnsProcess) execSetns() error {
 		return err
 	}
 	p.cmd.Process = process
+	p.pidfdFile = openPidfd(pid.Pid)
 	p.process.ops = p
 	return nil
 }
@@ -299,6 +333,7 @@ func (p *setnsProcess) forwardChildLogs() chan error {
 
 type initProcess struct {
 	cmd             *exec.Cmd
+	idmapFiles      []*os.File
 	messageSockPair filePair
 	logFilePair     filePair
 	config          *initConfig
@@ -309,6 +344,7 @@ type initProcess struct {
 	process         *Process
 	bootstrapData   io.Reader
 	sharePidns      bool
+	pidfdFile       *os.File
 }
 
 func (p *initProcess) pid() int {
@@ -353,19 +389,29 @@ func (p *initProcess) waitForChildExit(childPid int) error {
 		return err
 	}
 	p.cmd.Process = process
+	p.pidfdFile = openPidfd(childPid)
 	p.process.ops = p
 	return nil
 }
 
 func (p *initProcess) start() (retErr error) {
 	defer p.messageSockPair.parent.Close() //nolint: errcheck
-	err := p.cmd.Start()
+	cgroupDir := p.prepareCgroup()
+	var err error
+	p.cmd, err = startInCgroup(p.cmd, cgroupDir)
 	p.process.ops = p
 	// close the write-side of the pipes (controlled by child)
 	_ = p.messageSockPair.child.Close()
 	_ = p.logFilePair.child.Close()
+	// the child has its own copies of the ID-mapped mounts
+	for _, f := range p.idmapFiles {
+		_ = f.Close()
+	}
 	if err != nil {
 		p.process.ops = nil
+		if cgroupDir != "" {
+			_ = p.manager.Destroy()
+		}
 		return fmt.Errorf("unable to start init: %w", err)
 	}
 
@@ -446,6 +492,9 @@ func (p *initProcess) start() (retErr error) {
 	if err := p.waitForChildExit(childPid); err != nil {
 		return fmt.Errorf("error waiting for our first child to exit: %w", err)
 	}
+	if err := setFinalCPUAffinity(p.config.CPUAffinity, p.pid()); err != nil {
+		return err
+	}
 
 	if err := p.createNetworkInterfaces(); err != nil {
 		return fmt.Errorf("error creating network interfaces: %w", err)
@@ -618,6 +667,32 @@ func (p *initProcess) start() (retErr error) {
 	return nil
 }
 
+// prepareCgroup creates the container's cgroup in advance and sets its
+// resource limits, so that init can be started directly in it, and returns
+// its path. An empty string is returned if this is not possible, in which
+// case init is moved to its cgroup by manager.Apply after it is started.
+func (p *initProcess) prepareCgroup() string {
+	if !cgroups.IsCgroup2UnifiedMode() {
+		return ""
+	}
+	// A systemd scope can not be created without a process in it.
+	c := p.config.Config.Cgroups
+	if c == nil || c.Systemd {
+		return ""
+	}
+	if err := p.manager.Apply(-1); err != nil {
+		logrus.Warnf("unable to create cgroup in advance: %v", err)
+		return ""
+	}
+	// The limits must be enforced from the start, as init runs in the
+	// cgroup as soon as it is cloned.
+	if err := p.manager.Set(c.Resources); err != nil {
+		logrus.Warnf("unable to set cgroup limits in advance: %v", err)
+		return ""
+	}
+	return p.manager.Path("")
+}
+
 func (p *initProcess) wait() (*os.ProcessState, error) {
 	err := p.cmd.Wait()
 	// we should kill all processes in cgroup when init is died if we use host PID namespace
@@ -672,17 +747,19 @@ func (p *initProcess) createNetworkInterfaces() error {
 		if err := strategy.create(n, p.pid()); err != nil {
 			return err
 		}
+		// Keep the index of the host interface, to destroy it later.
+		config.HostInterfaceIndex = n.HostInterfaceIndex
 		p.config.Networks = append(p.config.Networks, n)
 	}
 	return nil
 }
 
 func (p *initProcess) signal(sig os.Signal) error {
-	s, ok := sig.(unix.Signal)
-	if !ok {
-		return errors.New("os: unsupported signal type")
-	}
-	return unix.Kill(p.pid(), s)
+	return signalPidfd(p.pidfdFile, p.pid(), sig)
+}
+
+func (p *initProcess) pidfd() *os.File {
+	return p.pidfdFile
 }
 
 func (p *initProcess) setExternalDescriptors(newFds []string) {
@@ -799,6 +876,62 @@ func (p *Process) InitializeIO(rootuid, rootgid int) (i *IO, err error) {
 	return i, nil
 }
 
+// startInCgroup starts cmd directly in the cgroup v2 directory dir, using
+// clone3(2) with CLONE_INTO_CGROUP. If this is not possible (an old kernel
+// or Go version, or an empty dir), cmd is started as usual, and the caller
+// is responsible for moving it to the cgroup.
+//
+// As exec.Cmd can not be started twice, the returned command is a copy of
+// cmd if the first attempt has failed.
+func startInCgroup(cmd *exec.Cmd, dir string) (*exec.Cmd, error) {
+	if dir == "" || !cgroups.IsCgroup2UnifiedMode() {
+		return cmd, cmd.Start()
+	}
+	fd, err := os.OpenFile(dir, unix.O_PATH|unix.O_DIRECTORY|unix.O_CLOEXEC, 0)
+	if err != nil {
+		logrus.Debugf("unable to open cgroup %q: %v", dir, err)
+		return cmd, cmd.Start()
+	}
+	defer fd.Close()
+	if !setCgroupFD(cmd, int(fd.Fd())) {
+		return cmd, cmd.Start()
+	}
+	logrus.Debugf("using CLONE_INTO_CGROUP %q", dir)
+	err = cmd.Start()
+	if err == nil || !unsetCgroupFD(cmd) {
+		return cmd, err
+	}
+	logrus.Debugf("CLONE_INTO_CGROUP failed: %v; retrying without", err)
+	retry := &exec.Cmd{
+		Path:        cmd.Path,
+		Args:        cmd.Args,
+		Env:         cmd.Env,
+		Dir:         cmd.Dir,
+		Stdin:       cmd.Stdin,
+		Stdout:      cmd.Stdout,
+		Stderr:      cmd.Stderr,
+		ExtraFiles:  cmd.ExtraFiles,
+		SysProcAttr: cmd.SysProcAttr,
+	}
+	return retry, retry.Start()
+}
+
+// setFinalCPUAffinity sets the final CPU affinity from aff, if any, of the
+// process, which must already be in the container cgroup.
+func setFinalCPUAffinity(aff *configs.CPUAffinity, pid int) error {
+	if aff == nil || aff.Final == "" {
+		return nil
+	}
+	final, err := configs.ToCPUSet(aff.Final)
+	if err != nil {
+		return err
+	}
+	if err := unix.SchedSetaffinity(pid, final); err != nil {
+		return fmt.Errorf("error setting final CPU affinity: %w", err)
+	}
+	return nil
+}
+
 // initWaiter returns a channel to wait on for making sure
 // runc init has finished the initial setup.
 func initWaiter(r io.Reader) chan error {
@@ -0,0 +1,78 @@
+package libcontainer
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/opencontainers/runc/libcontainer/cgroups"
+	"github.com/opencontainers/runc/libcontainer/cgroups/fs2"
+)
+
+func TestStartInCgroup(t *testing.T) {
+	if !cgroups.IsCgroup2UnifiedMode() {
+		t.Skip("cgroup v2 is required")
+	}
+	if os.Geteuid() != 0 {
+		t.Skip("root is required")
+	}
+	if !setCgroupFD(&exec.Cmd{}, -1) {
+		t.Skip("CLONE_INTO_CGROUP is not supported by os/exec")
+	}
+	name := "runc-test-clone3-" + strconv.Itoa(os.Getpid())
+	dir := filepath.Join(fs2.UnifiedMountpoint, name)
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(dir)
+
+	var out bytes.Buffer
+	cmd := exec.Command("cat", "/proc/self/cgroup")
+	cmd.Stdout = &out
+	cmd, err := startInCgroup(cmd, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Wait(); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := out.String(), "0::/"+name+"\n"; got != want {
+		t.Fatalf("expected cgroup %q, got %q", want, got)
+	}
+}
+
+func TestStartInCgroupFallback(t *testing.T) {
+	if !cgroups.IsCgroup2UnifiedMode() {
+		t.Skip("cgroup v2 is required")
+	}
+	// A directory which is not a cgroup makes clone3(2) fail, so the
+	// process is expected to be started again without CLONE_INTO_CGROUP.
+	cmd := exec.Command("true")
+	started, err := startInCgroup(cmd, t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := started.Wait(); err != nil {
+		t.Fatal(err)
+	}
+	if setCgroupFD(&exec.Cmd{}, -1) && started == cmd {
+		t.Fatal("expected the process to be started again without CLONE_INTO_CGROUP")
+	}
+}
+
+func TestStartInCgroupNoDir(t *testing.T) {
+	cmd := exec.Command("true")
+	started, err := startInCgroup(cmd, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := started.Wait(); err != nil {
+		t.Fatal(err)
+	}
+	if started != cmd {
+		t.Fatal("expected the process to be started once")
+	}
+}
@@ -19,6 +19,7 @@ func newRestoredProcess(cmd *exec.Cmd, fds []string) (*restoredProcess, error) {
 		cmd:              cmd,
 		processStartTime: stat.StartTime,
 		fds:              fds,
+		pidfdFile:        openPidfd(pid),
 	}, nil
 }
 
@@ -26,6 +27,7 @@ type restoredProcess struct {
 	cmd              *exec.Cmd
 	processStartTime uint64
 	fds              []string
+	pidfdFile        *os.File
 }
 
 func (p *restoredProcess) start() error {
@@ -63,7 +65,14 @@ func (p *restoredProcess) startTime() (uint64, error) {
 }
 
 func (p *restoredProcess) signal(s os.Signal) error {
-	return p.cmd.Process.Signal(s)
+	if p.pidfdFile == nil {
+		return p.cmd.Process.Signal(s)
+	}
+	return signalPidfd(p.pidfdFile, p.pid(), s)
+}
+
+func (p *restoredProcess) pidfd() *os.File {
+	return p.pidfdFile
 }
 
 func (p *restoredProcess) externalDescriptors() []string {
@@ -108,6 +117,14 @@ func (p *nonChildProcess) startTime() (uint64, error) {
 }
 
 func (p *nonChildProcess) signal(s os.Signal) error {
+	pidfd, err := openPidfdStartTime(p.processPid, p.processStartTime)
+	if err != nil {
+		return err
+	}
+	if pidfd != nil {
+		defer pidfd.Close()
+		return signalPidfd(pidfd, p.processPid, s)
+	}
 	proc, err := os.FindProcess(p.processPid)
 	if err != nil {
 		return err
@@ -115,6 +132,12 @@ func (p *nonChildProcess) signal(s os.Signal) error {
 	return proc.Signal(s)
 }
 
+// pidfd returns nil, as a pidfd is only opened for a process which is not a
+// child when it is needed, to be closed right after (see initPidfd).
+func (p *nonChildProcess) pidfd() *os.File {
+	return nil
+}
+
 func (p *nonChildProcess) externalDescriptors() []string {
 	return p.fds
 }
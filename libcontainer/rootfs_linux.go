@@ -50,14 +50,17 @@ func needsSetupDev(config *configs.Config) bool {
 // prepareRootfs sets up the devices, mount points, and filesystems for use
 // inside a new mount namespace. It doesn't set anything as ro. You must call
 // finalizeRootfs after this function to finish setting up the rootfs.
-func prepareRootfs(pipe io.ReadWriter, iConfig *initConfig, mountFds []int) (err error) {
+func prepareRootfs(pipe io.ReadWriter, iConfig *initConfig, mountFds mountFds) (err error) {
 	config := iConfig.Config
 	if err := prepareRoot(config); err != nil {
 		return fmt.Errorf("error preparing rootfs: %w", err)
 	}
 
-	if mountFds != nil && len(mountFds) != len(config.Mounts) {
-		return fmt.Errorf("malformed mountFds slice. Expected size: %v, got: %v. Slice: %v", len(config.Mounts), len(mountFds), mountFds)
+	if mountFds.sourceFds != nil && len(mountFds.sourceFds) != len(config.Mounts) {
+		return fmt.Errorf("malformed mountFds slice. Expected size: %v, got: %v. Slice: %v", len(config.Mounts), len(mountFds.sourceFds), mountFds.sourceFds)
+	}
+	if mountFds.idmapFds != nil && len(mountFds.idmapFds) != len(config.Mounts) {
+		return fmt.Errorf("malformed idmapFds slice. Expected size: %v, got: %v. Slice: %v", len(config.Mounts), len(mountFds.idmapFds), mountFds.idmapFds)
 	}
 
 	mountConfig := &mountConfig{
@@ -71,8 +74,15 @@ func prepareRootfs(pipe io.ReadWriter, iConfig *initConfig, mountFds []int) (err
 	for i, m := range config.Mounts {
 		// Just before the loop we checked that if not empty, len(mountFds) == len(config.Mounts).
 		// Therefore, we can access mountFds[i] without any concerns.
-		if mountFds != nil && mountFds[i] != -1 {
-			mountConfig.fd = &mountFds[i]
+		mountConfig.fd = nil
+		if mountFds.sourceFds != nil && mountFds.sourceFds[i] != -1 {
+			mountConfig.fd = &mountFds.sourceFds[i]
+		}
+		if m.IsIDMapped() {
+			if mountFds.idmapFds == nil || mountFds.idmapFds[i] == -1 {
+				return fmt.Errorf("error mounting %q to rootfs at %q: missing ID-mapped mount", m.Source, m.Destination)
+			}
+			mountConfig.fd = &mountFds.idmapFds[i]
 		}
 
 		if err := mountToRootfs(m, mountConfig); err != nil {
@@ -1084,6 +1094,11 @@ func mountPropagate(m *configs.Mount, rootfs string, mountLabel string, mountFd
 	}
 
 	if err := utils.WithProcfd(rootfs, m.Destination, func(procfd string) error {
+		if m.IsIDMapped() {
+			// The ID-mapped mount has already been created (with the
+			// bind flags) by the parent, we only need to attach it.
+			return moveMount(*mountFd, m.Source, m.Destination, procfd)
+		}
 		return mount(source, m.Destination, procfd, m.Device, uintptr(flags), data)
 	}); err != nil {
 		return err
@@ -0,0 +1,123 @@
+package seccomp
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+// Call is a system call a seccomp program can be evaluated against.
+type Call struct {
+	// Name is the name or the number of the system call.
+	Name string
+	// Arch is the architecture of the system call, as a SCMP_ARCH_* name or
+	// a libseccomp one (such as x86_64). If empty, the native one is used.
+	Arch string
+	// Args are the arguments of the system call, the missing ones being 0.
+	Args []uint64
+}
+
+// ParseCall parses a system call written as name(arch=x86_64, arg0, ...),
+// the arguments and parentheses being optional. The arguments are numbers,
+// in C notation.
+func ParseCall(s string) (*Call, error) {
+	s = strings.TrimSpace(s)
+	call := &Call{Name: s}
+	if i := strings.IndexByte(s, '('); i >= 0 {
+		if !strings.HasSuffix(s, ")") {
+			return nil, fmt.Errorf("invalid system call %q: missing closing parenthesis", s)
+		}
+		call.Name = strings.TrimSpace(s[:i])
+		if args := strings.TrimSpace(s[i+1 : len(s)-1]); args != "" {
+			for _, arg := range strings.Split(args, ",") {
+				arg = strings.TrimSpace(arg)
+				if strings.HasPrefix(arg, "arch=") {
+					call.Arch = strings.TrimPrefix(arg, "arch=")
+					continue
+				}
+				v, err := parseArg(arg)
+				if err != nil {
+					return nil, fmt.Errorf("invalid system call %q: %w", s, err)
+				}
+				call.Args = append(call.Args, v)
+			}
+		}
+	}
+	if call.Name == "" {
+		return nil, fmt.Errorf("invalid system call %q: missing name", s)
+	}
+	if len(call.Args) > 6 {
+		return nil, fmt.Errorf("invalid system call %q: more than 6 arguments", s)
+	}
+	return call, nil
+}
+
+// parseArg parses a system call argument, negative values being converted
+// like in C.
+func parseArg(s string) (uint64, error) {
+	if strings.HasPrefix(s, "-") {
+		v, err := strconv.ParseInt(s, 0, 64)
+		return uint64(v), err
+	}
+	return strconv.ParseUint(s, 0, 64)
+}
+
+func (c *Call) String() string {
+	var args []string
+	if c.Arch != "" {
+		args = append(args, "arch="+c.Arch)
+	}
+	for _, arg := range c.Args {
+		args = append(args, "0x"+strconv.FormatUint(arg, 16))
+	}
+	return c.Name + "(" + strings.Join(args, ", ") + ")"
+}
+
+// Seccomp return values, from <linux/seccomp.h>.
+const (
+	retKillProcess = 0x80000000
+	retKillThread  = 0x00000000
+	retTrap        = 0x00030000
+	retErrno       = 0x00050000
+	retUserNotif   = 0x7fc00000
+	retTrace       = 0x7ff00000
+	retLog         = 0x7ffc0000
+	retAllow       = 0x7fff0000
+
+	retActionFull = 0xffff0000
+	retData       = 0x0000ffff
+)
+
+// Action is the value returned by a seccomp program, made of an action and
+// its data.
+type Action uint32
+
+// String returns the name of the action in a runtime spec, followed by its
+// data, if any.
+func (a Action) String() string {
+	data := uint32(a) & retData
+	switch uint32(a) & retActionFull {
+	case retKillProcess:
+		return "SCMP_ACT_KILL_PROCESS"
+	case retKillThread:
+		return "SCMP_ACT_KILL_THREAD"
+	case retTrap:
+		return "SCMP_ACT_TRAP"
+	case retErrno:
+		if name := unix.ErrnoName(unix.Errno(data)); name != "" {
+			return "SCMP_ACT_ERRNO(" + name + ")"
+		}
+		return "SCMP_ACT_ERRNO(" + strconv.FormatUint(uint64(data), 10) + ")"
+	case retUserNotif:
+		return "SCMP_ACT_NOTIFY"
+	case retTrace:
+		return "SCMP_ACT_TRACE(" + strconv.FormatUint(uint64(data), 10) + ")"
+	case retLog:
+		return "SCMP_ACT_LOG"
+	case retAllow:
+		return "SCMP_ACT_ALLOW"
+	}
+	return fmt.Sprintf("unknown action %#x", uint32(a))
+}
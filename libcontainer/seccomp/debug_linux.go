@@ -0,0 +1,290 @@
+//go:build cgo && seccomp
+// +build cgo,seccomp
+
+package seccomp
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	libseccomp "github.com/seccomp/libseccomp-golang"
+	"golang.org/x/net/bpf"
+
+	"github.com/opencontainers/runc/libcontainer/configs"
+	"github.com/opencontainers/runc/libcontainer/seccomp/patchbpf"
+	"github.com/opencontainers/runc/libcontainer/utils"
+)
+
+// Offsets of the fields of struct seccomp_data, from <linux/seccomp.h>.
+const (
+	dataNr   = 0
+	dataArch = 4
+	dataIP   = 8
+	dataArgs = 16
+	dataLen  = 64
+)
+
+// x32SyscallBit is set in the numbers of the x32 system calls.
+const x32SyscallBit = 0x40000000
+
+// Program is a seccomp BPF program, as loaded by InitSeccomp.
+type Program struct {
+	insns   []bpf.Instruction
+	stubLen int
+	vm      *bpf.VM
+}
+
+// Compile returns the program InitSeccomp loads for config, without loading
+// it.
+func Compile(config *configs.Seccomp) (*Program, error) {
+	filter, err := buildFilter(config)
+	if err != nil {
+		return nil, err
+	}
+	defer filter.Release()
+
+	insns, stubLen, err := patchbpf.Program(config, filter)
+	if err != nil {
+		return nil, fmt.Errorf("error patching filter: %w", err)
+	}
+	vm, err := bpf.NewVM(insns)
+	if err != nil {
+		return nil, fmt.Errorf("invalid seccomp program: %w", err)
+	}
+	return &Program{insns: insns, stubLen: stubLen, vm: vm}, nil
+}
+
+// Eval runs the program against call, and returns the resulting action.
+func (p *Program) Eval(call *Call) (Action, error) {
+	arch, err := parseArch(call.Arch)
+	if err != nil {
+		return 0, err
+	}
+	auditArch, err := patchbpf.AuditArch(arch)
+	if err != nil {
+		return 0, err
+	}
+	nr, err := syscallNumber(call.Name, arch)
+	if err != nil {
+		return 0, err
+	}
+
+	data := make([]byte, dataLen)
+	utils.NativeEndian.PutUint32(data[dataNr:], uint32(nr))
+	utils.NativeEndian.PutUint32(data[dataArch:], auditArch)
+	for i, arg := range call.Args {
+		utils.NativeEndian.PutUint64(data[dataArgs+8*i:], arg)
+	}
+	// The kernel loads the words of the seccomp data in native byte order,
+	// while the VM loads them in network byte order.
+	for i := 0; i < len(data); i += 4 {
+		binary.BigEndian.PutUint32(data[i:], utils.NativeEndian.Uint32(data[i:]))
+	}
+
+	ret, err := p.vm.Run(data)
+	if err != nil {
+		return 0, fmt.Errorf("error running seccomp program: %w", err)
+	}
+	return Action(uint32(ret)), nil
+}
+
+func parseArch(s string) (libseccomp.ScmpArch, error) {
+	if s == "" {
+		return libseccomp.GetNativeArch()
+	}
+	if name, ok := archs[s]; ok {
+		s = name
+	}
+	return libseccomp.GetArchFromString(s)
+}
+
+func syscallNumber(name string, arch libseccomp.ScmpArch) (int32, error) {
+	if nr, err := strconv.ParseInt(name, 0, 32); err == nil {
+		return int32(nr), nil
+	}
+	call, err := libseccomp.GetSyscallFromNameByArch(name, arch)
+	if err != nil {
+		return 0, fmt.Errorf("unknown system call %q on %s", name, arch)
+	}
+	return int32(call), nil
+}
+
+// dumpState is what is known of the state of a program before an
+// instruction, to annotate it.
+type dumpState struct {
+	// loaded is the offset of the seccomp data field loaded in A, or -1.
+	loaded int64
+	// arch is the SCMP_ARCH_* name of the architecture of the system call,
+	// or empty if unknown.
+	arch string
+}
+
+// merge returns the state known when coming from s or o.
+func (s *dumpState) merge(o dumpState) dumpState {
+	if s == nil {
+		return o
+	}
+	if s.loaded != o.loaded {
+		o.loaded = -1
+	}
+	if s.arch != o.arch {
+		o.arch = ""
+	}
+	return o
+}
+
+// Dump writes the program to w, one instruction per line, annotated with
+// the seccomp data fields loaded, and the architectures, system calls, jump
+// targets and actions of the instructions.
+func (p *Program) Dump(w io.Writer) error {
+	auditArchs := auditArchNames()
+	// Jumps only go forward, so the states before an instruction are all
+	// known once the previous instructions are annotated.
+	states := make([]*dumpState, len(p.insns)+1)
+	states[0] = &dumpState{loaded: -1}
+	jump := func(to int, st dumpState) {
+		if to < len(states) {
+			merged := states[to].merge(st)
+			states[to] = &merged
+		}
+	}
+	for i, insn := range p.insns {
+		if i == 0 && p.stubLen > 0 {
+			if _, err := fmt.Fprintln(w, "; -ENOSYS stub"); err != nil {
+				return err
+			}
+		}
+		if i == p.stubLen {
+			if _, err := fmt.Fprintln(w, "; filter"); err != nil {
+				return err
+			}
+		}
+
+		st := dumpState{loaded: -1}
+		if states[i] != nil {
+			st = *states[i]
+		}
+		var notes []string
+		switch insn := insn.(type) {
+		case bpf.LoadAbsolute:
+			notes = append(notes, "A = "+dataField(insn.Off))
+			st.loaded = int64(insn.Off)
+			jump(i+1, st)
+		case bpf.LoadConstant, bpf.LoadScratch, bpf.LoadIndirect, bpf.TXA,
+			bpf.ALUOpConstant, bpf.ALUOpX, bpf.NegateA:
+			// A no longer holds a field of the seccomp data as is.
+			st.loaded = -1
+			jump(i+1, st)
+		case bpf.JumpIf:
+			ifTrue, ifFalse := st, st
+			switch st.loaded {
+			case dataArch:
+				if name, ok := auditArchs[insn.Val]; ok {
+					notes = append(notes, name)
+					switch insn.Cond {
+					case bpf.JumpEqual:
+						ifTrue.arch = name
+					case bpf.JumpNotEqual:
+						ifFalse.arch = name
+					}
+				}
+			case dataNr:
+				// Comparisons with the x32 bit are not about a system call.
+				if insn.Cond == bpf.JumpBitsSet || insn.Cond == bpf.JumpBitsNotSet || insn.Val == x32SyscallBit {
+					break
+				}
+				if name := syscallName(insn.Val, st.arch); name != "" {
+					notes = append(notes, name)
+				}
+			}
+			trueTarget, falseTarget := i+1+int(insn.SkipTrue), i+1+int(insn.SkipFalse)
+			notes = append(notes, fmt.Sprintf("true: %d, false: %d", trueTarget, falseTarget))
+			jump(trueTarget, ifTrue)
+			jump(falseTarget, ifFalse)
+		case bpf.Jump:
+			notes = append(notes, fmt.Sprintf("goto %d", i+1+int(insn.Skip)))
+			jump(i+1+int(insn.Skip), st)
+		case bpf.RetConstant:
+			notes = append(notes, Action(insn.Val).String())
+		case bpf.RetA:
+		default:
+			jump(i+1, st)
+		}
+
+		line := fmt.Sprintf("%4d: %s", i, insn)
+		if len(notes) > 0 {
+			line = fmt.Sprintf("%-32s ; %s", line, strings.Join(notes, ", "))
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// auditArchNames returns the SCMP_ARCH_* names of the AUDIT_ARCH_* values.
+func auditArchNames() map[uint32]string {
+	names := make(map[uint32]string)
+	for name, libName := range archs {
+		// x32 shares AUDIT_ARCH_X86_64 with x86_64, its system calls being
+		// told apart by their numbers.
+		if name == "SCMP_ARCH_X32" {
+			continue
+		}
+		arch, err := libseccomp.GetArchFromString(libName)
+		if err != nil {
+			continue
+		}
+		auditArch, err := patchbpf.AuditArch(arch)
+		if err != nil {
+			continue
+		}
+		names[auditArch] = name
+	}
+	return names
+}
+
+// dataField returns the name of the seccomp data field at off.
+func dataField(off uint32) string {
+	switch {
+	case off == dataNr:
+		return "nr"
+	case off == dataArch:
+		return "arch"
+	case off == dataIP:
+		return "instruction_pointer"
+	case off == dataIP+4:
+		return "instruction_pointer >> 32"
+	case off >= dataArgs && off < dataLen:
+		arg := "args[" + strconv.Itoa(int(off-dataArgs)/8) + "]"
+		// The low word of an argument comes first on little endian.
+		if (off%8 == 0) != (utils.NativeEndian == binary.LittleEndian) {
+			arg += " >> 32"
+		}
+		return arg
+	}
+	return "[" + strconv.Itoa(int(off)) + "]"
+}
+
+// syscallName returns the name of the system call nr on the architecture
+// named arch, or an empty string if unknown.
+func syscallName(nr uint32, arch string) string {
+	if arch == "" {
+		return ""
+	}
+	if arch == "SCMP_ARCH_X86_64" && nr&x32SyscallBit != 0 {
+		arch = "SCMP_ARCH_X32"
+	}
+	scmpArch, err := libseccomp.GetArchFromString(archs[arch])
+	if err != nil {
+		return ""
+	}
+	name, err := libseccomp.ScmpSyscall(int32(nr)).GetNameByArch(scmpArch)
+	if err != nil {
+		return ""
+	}
+	return name
+}
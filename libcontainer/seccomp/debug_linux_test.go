@@ -0,0 +1,157 @@
+//go:build cgo && seccomp
+// +build cgo,seccomp
+
+package seccomp
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/bpf"
+
+	"github.com/opencontainers/runc/libcontainer/configs"
+)
+
+func TestParseCall(t *testing.T) {
+	for _, tc := range []struct {
+		in   string
+		want string
+	}{
+		{in: "getpid", want: "getpid()"},
+		{in: "getpid()", want: "getpid()"},
+		{in: " openat(arch=x86_64, -100, 0x10 ) ", want: "openat(arch=x86_64, 0xffffffffffffff9c, 0x10)"},
+		{in: "257(1,2,3,4,5,6)", want: "257(0x1, 0x2, 0x3, 0x4, 0x5, 0x6)"},
+		{in: "open(", want: ""},
+		{in: "(1)", want: ""},
+		{in: "open(foo)", want: ""},
+		{in: "open(1,2,3,4,5,6,7)", want: ""},
+	} {
+		call, err := ParseCall(tc.in)
+		if tc.want == "" {
+			if err == nil {
+				t.Errorf("%q: expected an error, got %s", tc.in, call)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: %v", tc.in, err)
+			continue
+		}
+		if got := call.String(); got != tc.want {
+			t.Errorf("%q: got %s, want %s", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestCompileEval(t *testing.T) {
+	errnoRet := uint(1)
+	config := &configs.Seccomp{
+		DefaultAction: configs.Errno,
+		Architectures: []string{"amd64"},
+		Syscalls: []*configs.Syscall{
+			{Name: "read", Action: configs.Allow},
+			{Name: "write", Action: configs.Allow},
+			{
+				Name:     "personality",
+				Action:   configs.Errno,
+				ErrnoRet: &errnoRet,
+				Args:     []*configs.Arg{{Index: 0, Value: 8, Op: configs.NotEqualTo}},
+			},
+			{
+				Name:   "personality",
+				Action: configs.Allow,
+				Args:   []*configs.Arg{{Index: 0, Value: 8, Op: configs.EqualTo}},
+			},
+		},
+	}
+	program, err := Compile(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		call string
+		want string
+	}{
+		{call: "read(arch=amd64)", want: "SCMP_ACT_ALLOW"},
+		{call: "write(arch=SCMP_ARCH_X86_64, 1, 0, 0)", want: "SCMP_ACT_ALLOW"},
+		{call: "getpid(arch=x86_64)", want: "SCMP_ACT_ERRNO(EPERM)"},
+		{call: "personality(arch=x86_64, 8)", want: "SCMP_ACT_ALLOW"},
+		{call: "personality(arch=x86_64, 0x100000008)", want: "SCMP_ACT_ERRNO(EPERM)"},
+		// Newer than all the system calls in the profile.
+		{call: "1000(arch=x86_64)", want: "SCMP_ACT_ERRNO(ENOSYS)"},
+		// Not an architecture of the profile.
+		{call: "read(arch=aarch64)", want: "SCMP_ACT_KILL_THREAD"},
+	} {
+		call, err := ParseCall(tc.call)
+		if err != nil {
+			t.Fatal(err)
+		}
+		action, err := program.Eval(call)
+		if err != nil {
+			t.Errorf("%s: %v", tc.call, err)
+			continue
+		}
+		if got := action.String(); got != tc.want {
+			t.Errorf("%s: got %s, want %s", tc.call, got, tc.want)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := program.Dump(&buf); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"; -ENOSYS stub", "; filter", "SCMP_ARCH_X86_64", "personality", "A = args[0]", "SCMP_ACT_ERRNO(ENOSYS)"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("dump does not contain %q:\n%s", want, buf.String())
+		}
+	}
+}
+
+func TestDumpMaskedArg(t *testing.T) {
+	config := &configs.Seccomp{
+		DefaultAction: configs.Errno,
+		Architectures: []string{"amd64"},
+		Syscalls: []*configs.Syscall{
+			{
+				Name:   "clone",
+				Action: configs.Allow,
+				Args:   []*configs.Arg{{Index: 0, Value: 0x7e020000, ValueTwo: 0, Op: configs.MaskEqualTo}},
+			},
+		},
+	}
+	program, err := Compile(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := program.Dump(&buf); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"clone", "A = args[0]", "and #2114060288"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("dump does not contain %q:\n%s", want, buf.String())
+		}
+	}
+
+	// Once masked, A is no longer the system call number, so the value it
+	// is compared with is not annotated as one.
+	program = &Program{insns: []bpf.Instruction{
+		bpf.LoadAbsolute{Off: dataArch, Size: 4},
+		bpf.JumpIf{Cond: bpf.JumpEqual, Val: 0xc000003e, SkipFalse: 4}, // AUDIT_ARCH_X86_64
+		bpf.LoadAbsolute{Off: dataNr, Size: 4},
+		bpf.ALUOpConstant{Op: bpf.ALUOpAnd, Val: 0xff},
+		bpf.JumpIf{Cond: bpf.JumpEqual, Val: 0, SkipFalse: 1}, // read on x86_64
+		bpf.RetConstant{Val: 0x7fff0000},                      // SCMP_ACT_ALLOW
+		bpf.RetConstant{Val: 0},
+	}}
+	buf.Reset()
+	if err := program.Dump(&buf); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range strings.Split(buf.String(), "\n") {
+		if strings.HasPrefix(line, "   4:") && strings.Contains(line, "read") {
+			t.Errorf("masked value annotated as a system call:\n%s", buf.String())
+		}
+	}
+}
@@ -0,0 +1,346 @@
+//go:build cgo && seccomp
+// +build cgo,seccomp
+
+package notify
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+	libseccomp "github.com/seccomp/libseccomp-golang"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/sys/unix"
+)
+
+// ErrAgentClosed is returned by Serve and Add after the agent is closed.
+var ErrAgentClosed = errors.New("seccomp agent closed")
+
+// Handler handles a notified system call, and returns the response to it.
+type Handler func(req *Request) Response
+
+// Agent handles the system calls notified by containers. The zero value is
+// not usable, use NewAgent instead.
+type Agent struct {
+	// Default handles the system calls without a handler of their own. If
+	// nil, they are continued.
+	Default Handler
+	// Started, if not nil, is called when a container is added, before any
+	// of its notifications is handled. If it returns an error, the container
+	// is rejected and its seccomp fd is closed, so its notified system calls
+	// fail with ENOSYS.
+	Started func(c *Container) error
+	// Stopped, if not nil, is called once all the processes of a container
+	// using its filter exited, or the container was closed, after the
+	// handlers of its pending notifications returned.
+	Stopped func(c *Container)
+
+	mu         sync.Mutex
+	closed     bool
+	handlers   map[string]Handler
+	listeners  map[net.Listener]struct{}
+	containers map[*Container]struct{}
+}
+
+// NewAgent returns an Agent without any handler.
+func NewAgent() *Agent {
+	return &Agent{
+		handlers:   make(map[string]Handler),
+		listeners:  make(map[net.Listener]struct{}),
+		containers: make(map[*Container]struct{}),
+	}
+}
+
+// Handle registers h as the handler of the system call name, on all the
+// architectures. It replaces any handler previously registered for name.
+func (a *Agent) Handle(name string, h Handler) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.handlers[name] = h
+}
+
+func (a *Agent) handler(name string) Handler {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if h, ok := a.handlers[name]; ok {
+		return h
+	}
+	if a.Default != nil {
+		return a.Default
+	}
+	return func(*Request) Response { return Continue() }
+}
+
+// Serve accepts connections on l, receives the container seccomp fds sent on
+// them, and adds the containers. It returns when accepting fails, or with
+// ErrAgentClosed once the agent is closed. Errors receiving a seccomp fd are
+// logged.
+func (a *Agent) Serve(l net.Listener) error {
+	a.mu.Lock()
+	if a.closed {
+		a.mu.Unlock()
+		return ErrAgentClosed
+	}
+	a.listeners[l] = struct{}{}
+	a.mu.Unlock()
+	defer func() {
+		a.mu.Lock()
+		delete(a.listeners, l)
+		a.mu.Unlock()
+	}()
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			a.mu.Lock()
+			closed := a.closed
+			a.mu.Unlock()
+			if closed {
+				return ErrAgentClosed
+			}
+			return err
+		}
+		uconn, ok := conn.(*net.UnixConn)
+		if !ok {
+			conn.Close()
+			logrus.Errorf("seccomp agent: connection is not a unix socket: %s", conn.RemoteAddr())
+			continue
+		}
+		state, fd, err := RecvSeccompFd(uconn)
+		conn.Close()
+		if err != nil {
+			logrus.Errorf("seccomp agent: unable to receive seccomp fd: %v", err)
+			continue
+		}
+		if _, err := a.Add(state, fd); err != nil && !errors.Is(err, ErrAgentClosed) {
+			logrus.Errorf("seccomp agent: container %s rejected: %v", state.State.ID, err)
+		}
+	}
+}
+
+// Add starts handling the notifications of a container received on the
+// seccomp fd. The agent takes ownership of fd, which is closed on error.
+func (a *Agent) Add(state *specs.ContainerProcessState, fd int) (*Container, error) {
+	stopped, stop, err := os.Pipe()
+	if err != nil {
+		unix.Close(fd)
+		return nil, err
+	}
+	c := &Container{
+		State:   state,
+		agent:   a,
+		fd:      libseccomp.ScmpFd(fd),
+		stop:    stop,
+		stopped: stopped,
+		done:    make(chan struct{}),
+	}
+	if a.Started != nil {
+		if err := a.Started(c); err != nil {
+			c.release()
+			return nil, err
+		}
+	}
+	a.mu.Lock()
+	if a.closed {
+		a.mu.Unlock()
+		c.release()
+		return nil, ErrAgentClosed
+	}
+	a.containers[c] = struct{}{}
+	a.mu.Unlock()
+	go c.run()
+	return c, nil
+}
+
+// Close stops the agent: its listeners are closed, and so are all its
+// containers, once the handlers of their pending notifications returned.
+// It must not be called from a handler.
+func (a *Agent) Close() error {
+	a.mu.Lock()
+	a.closed = true
+	var err error
+	for l := range a.listeners {
+		if lerr := l.Close(); lerr != nil && err == nil {
+			err = lerr
+		}
+	}
+	containers := make([]*Container, 0, len(a.containers))
+	for c := range a.containers {
+		containers = append(containers, c)
+	}
+	a.mu.Unlock()
+	for _, c := range containers {
+		c.Close()
+	}
+	return err
+}
+
+// RecvSeccompFd receives a seccomp fd sent by runc on conn, along with the
+// state of the container process using it. The other fds sent are closed.
+func RecvSeccompFd(conn *net.UnixConn) (*specs.ContainerProcessState, int, error) {
+	const maxStateLen = 4096
+	buf := make([]byte, maxStateLen)
+	oob := make([]byte, unix.CmsgSpace(4))
+	n, oobn, _, _, err := conn.ReadMsgUnix(buf, oob)
+	if err != nil {
+		return nil, -1, err
+	}
+	if n >= maxStateLen || oobn != len(oob) {
+		return nil, -1, fmt.Errorf("recvfd: incorrect number of bytes read (n=%d oobn=%d)", n, oobn)
+	}
+	scms, err := unix.ParseSocketControlMessage(oob[:oobn])
+	if err != nil {
+		return nil, -1, err
+	}
+	if len(scms) != 1 {
+		return nil, -1, fmt.Errorf("recvfd: number of SCMs is not 1: %d", len(scms))
+	}
+	fds, err := unix.ParseUnixRights(&scms[0])
+	if err != nil {
+		return nil, -1, err
+	}
+	state := &specs.ContainerProcessState{}
+	if err := json.Unmarshal(buf[:n], state); err != nil {
+		closeFds(fds)
+		return nil, -1, fmt.Errorf("cannot parse OCI state: %w", err)
+	}
+	fd, err := seccompFd(state.Fds, fds)
+	if err != nil {
+		closeFds(fds)
+		return nil, -1, err
+	}
+	return state, fd, nil
+}
+
+// seccompFd returns the seccomp fd and closes the other fds in fds, which
+// are named by names. In case of error, no fd is closed.
+func seccompFd(names []string, fds []int) (int, error) {
+	if len(names) != len(fds) {
+		return -1, errors.New("malformed container process state fds")
+	}
+	idx := -1
+	for i, name := range names {
+		if name != specs.SeccompFdName {
+			continue
+		}
+		if idx != -1 {
+			return -1, errors.New("seccomp fd found twice in container process state fds")
+		}
+		idx = i
+	}
+	if idx == -1 {
+		return -1, errors.New("seccomp fd not found in container process state fds")
+	}
+	for i, fd := range fds {
+		if i != idx {
+			unix.Close(fd)
+		}
+	}
+	return fds[idx], nil
+}
+
+func closeFds(fds []int) {
+	for _, fd := range fds {
+		unix.Close(fd)
+	}
+}
+
+// Container is a container whose notifications are handled by an Agent.
+type Container struct {
+	// State is the state of the container process the seccomp fd was
+	// received with. Its Metadata is the linux.seccomp.listenerMetadata of
+	// the container config.
+	State *specs.ContainerProcessState
+
+	agent *Agent
+	fd    libseccomp.ScmpFd
+	// stop is closed to stop handling the notifications.
+	stop, stopped *os.File
+	stopOnce      sync.Once
+	// wg tracks the running handlers.
+	wg   sync.WaitGroup
+	done chan struct{}
+}
+
+// Done returns a channel closed once the container is stopped.
+func (c *Container) Done() <-chan struct{} {
+	return c.done
+}
+
+// Close stops handling the notifications of the container, and waits for
+// the handlers of the pending ones to return. The seccomp fd is closed, so
+// the notified system calls of the container fail with ENOSYS afterwards.
+// It must not be called from a handler of the container.
+func (c *Container) Close() error {
+	c.stopOnce.Do(func() { c.stop.Close() })
+	<-c.done
+	return nil
+}
+
+func (c *Container) release() {
+	unix.Close(int(c.fd))
+	c.stopOnce.Do(func() { c.stop.Close() })
+	c.stopped.Close()
+}
+
+// run handles the notifications of the container until it is stopped.
+func (c *Container) run() {
+	defer func() {
+		c.wg.Wait()
+		c.release()
+		a := c.agent
+		a.mu.Lock()
+		delete(a.containers, c)
+		a.mu.Unlock()
+		if a.Stopped != nil {
+			a.Stopped(c)
+		}
+		close(c.done)
+	}()
+
+	pfds := []unix.PollFd{
+		{Fd: int32(c.fd), Events: unix.POLLIN},
+		{Fd: int32(c.stopped.Fd()), Events: unix.POLLIN},
+	}
+	for {
+		pfds[0].Revents, pfds[1].Revents = 0, 0
+		if _, err := unix.Poll(pfds, -1); err != nil {
+			if errors.Is(err, unix.EINTR) {
+				continue
+			}
+			logrus.Errorf("seccomp agent: container %s: poll: %v", c.State.State.ID, err)
+			return
+		}
+		if pfds[1].Revents != 0 {
+			return
+		}
+		if pfds[0].Revents&unix.POLLIN == 0 {
+			// All the processes using the filter exited.
+			return
+		}
+		notif, err := libseccomp.NotifReceive(c.fd)
+		if err != nil {
+			// The process may have been killed in the meantime.
+			if errors.Is(err, unix.ENOENT) {
+				continue
+			}
+			logrus.Errorf("seccomp agent: container %s: unable to receive notification: %v", c.State.State.ID, err)
+			return
+		}
+		req := &Request{ScmpNotifReq: notif, Container: c}
+		// An unknown system call is still handled, by the default handler.
+		req.Name, _ = notif.Data.Syscall.GetNameByArch(notif.Data.Arch)
+		h := c.agent.handler(req.Name)
+		c.wg.Add(1)
+		go func() {
+			defer c.wg.Done()
+			if err := req.respond(h(req)); err != nil && !errors.Is(err, ErrNotValid) {
+				logrus.Errorf("seccomp agent: container %s: unable to respond to %s: %v", c.State.State.ID, req.Name, err)
+			}
+		}()
+	}
+}
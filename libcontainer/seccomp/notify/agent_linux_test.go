@@ -0,0 +1,114 @@
+//go:build cgo && seccomp
+// +build cgo,seccomp
+
+package notify
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"os"
+	"testing"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+	"golang.org/x/sys/unix"
+)
+
+func TestRecvSeccompFd(t *testing.T) {
+	fds, err := unix.Socketpair(unix.AF_UNIX, unix.SOCK_STREAM|unix.SOCK_CLOEXEC, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sender := os.NewFile(uintptr(fds[0]), "sender")
+	defer sender.Close()
+	f := os.NewFile(uintptr(fds[1]), "receiver")
+	conn, err := net.FileConn(f)
+	f.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	// Send two pipe fds, the second one standing for the seccomp fd.
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+	sent := &specs.ContainerProcessState{
+		Version:  specs.Version,
+		Fds:      []string{"other", specs.SeccompFdName},
+		Pid:      1,
+		Metadata: "foo",
+		State:    specs.State{ID: "test"},
+	}
+	data, err := json.Marshal(sent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	oob := unix.UnixRights(int(r.Fd()), int(w.Fd()))
+	if err := unix.Sendmsg(int(sender.Fd()), data, oob, nil, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	state, fd, err := RecvSeccompFd(conn.(*net.UnixConn))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer unix.Close(fd)
+	if state.State.ID != "test" || state.Metadata != "foo" {
+		t.Errorf("unexpected state: %+v", state)
+	}
+	// The received seccomp fd is the write end of the pipe.
+	if _, err := unix.Write(fd, []byte("x")); err != nil {
+		t.Errorf("seccomp fd is not the one sent: %v", err)
+	}
+}
+
+func TestSeccompFd(t *testing.T) {
+	for _, tc := range []struct {
+		names []string
+		ok    bool
+	}{
+		{names: []string{specs.SeccompFdName}, ok: true},
+		{names: []string{"a", specs.SeccompFdName, "b"}, ok: true},
+		{names: []string{"a", "b"}},
+		{names: []string{specs.SeccompFdName, specs.SeccompFdName}},
+		{names: []string{specs.SeccompFdName, "a", "b", "c"}},
+	} {
+		fds := make([]int, 0, 3)
+		for range tc.names {
+			if len(fds) == 3 {
+				break
+			}
+			fd, err := unix.Dup(0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			fds = append(fds, fd)
+		}
+		fd, err := seccompFd(tc.names, fds)
+		if !tc.ok {
+			if err == nil {
+				t.Errorf("%v: expected an error", tc.names)
+			}
+			closeFds(fds)
+			continue
+		}
+		if err != nil {
+			t.Errorf("%v: %v", tc.names, err)
+			continue
+		}
+		// All the other fds are closed.
+		for _, other := range fds {
+			_, err := unix.FcntlInt(uintptr(other), unix.F_GETFD, 0)
+			if other == fd && err != nil {
+				t.Errorf("%v: seccomp fd closed: %v", tc.names, err)
+			} else if other != fd && !errors.Is(err, unix.EBADF) {
+				t.Errorf("%v: fd %d not closed", tc.names, other)
+			}
+		}
+		unix.Close(fd)
+	}
+}
@@ -0,0 +1,12 @@
+// Package notify implements seccomp user notification agents, which handle
+// the system calls of container processes matching SCMP_ACT_NOTIFY rules.
+//
+// The seccomp fds of the containers are sent by runc to the unix socket set
+// as the linux.seccomp.listenerPath of their config, along with the container
+// process state. An Agent receives them on its listeners, and calls the
+// handler registered for each notified system call, from which the arguments
+// of the system call can be read, and fds installed in the calling process.
+//
+// The package requires runc to be built with seccomp support, as well as
+// Linux >= 5.7 and libseccomp >= 2.5.0.
+package notify
@@ -0,0 +1,251 @@
+//go:build cgo && seccomp
+// +build cgo,seccomp
+
+package notify
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"strconv"
+	"unsafe"
+
+	libseccomp "github.com/seccomp/libseccomp-golang"
+	"golang.org/x/sys/unix"
+)
+
+/*
+#include <linux/types.h>
+#include <linux/ioctl.h>
+#include <linux/seccomp.h>
+
+// Copied from <linux/seccomp.h>.
+
+#ifndef SECCOMP_IOCTL_NOTIF_ADDFD
+struct seccomp_notif_addfd {
+	__u64 id;
+	__u32 flags;
+	__u32 srcfd;
+	__u32 newfd;
+	__u32 newfd_flags;
+};
+#	define SECCOMP_IOCTL_NOTIF_ADDFD _IOW('!', 3, struct seccomp_notif_addfd)
+#endif
+const unsigned long C_IOCTL_NOTIF_ADDFD = SECCOMP_IOCTL_NOTIF_ADDFD;
+
+#ifndef SECCOMP_ADDFD_FLAG_SETFD
+#	define SECCOMP_ADDFD_FLAG_SETFD (1UL << 0)
+#endif
+const __u32 C_ADDFD_FLAG_SETFD = SECCOMP_ADDFD_FLAG_SETFD;
+
+#ifndef SECCOMP_ADDFD_FLAG_SEND
+#	define SECCOMP_ADDFD_FLAG_SEND (1UL << 1)
+#endif
+const __u32 C_ADDFD_FLAG_SEND = SECCOMP_ADDFD_FLAG_SEND;
+*/
+import "C"
+
+// ErrNotValid is returned when a notification is no longer valid, because
+// the calling process was interrupted or killed in the meantime.
+var ErrNotValid = errors.New("seccomp notification no longer valid")
+
+// Request is a notified system call.
+type Request struct {
+	*libseccomp.ScmpNotifReq
+	// Name is the name of the system call, or empty if unknown.
+	Name string
+	// Container is the container of the calling process.
+	Container *Container
+}
+
+// Valid returns ErrNotValid if the request is no longer valid. Once the
+// calling process is gone, its pid may be reused, so the request must be
+// checked to be valid after accessing the process through its pid, before
+// acting on what was accessed.
+func (r *Request) Valid() error {
+	if err := libseccomp.NotifIDValid(r.Container.fd, r.ID); err != nil {
+		if errors.Is(err, unix.ENOENT) {
+			return ErrNotValid
+		}
+		return err
+	}
+	return nil
+}
+
+// openMem opens the memory of the calling process, checking that the pid
+// was not reused.
+func (r *Request) openMem() (*os.File, error) {
+	f, err := os.Open("/proc/" + strconv.FormatUint(uint64(r.Pid), 10) + "/mem")
+	if err != nil {
+		return nil, err
+	}
+	if err := r.Valid(); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return f, nil
+}
+
+// ReadMemory reads len(buf) bytes at addr in the memory of the calling
+// process. Other threads of the process can change the memory at any time,
+// so what is read must not be trusted to decide to continue the system call.
+func (r *Request) ReadMemory(buf []byte, addr uint64) error {
+	f, err := r.openMem()
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if _, err := f.ReadAt(buf, int64(addr)); err != nil {
+		return err
+	}
+	return r.Valid()
+}
+
+// ReadString reads the NUL-terminated string at addr in the memory of the
+// calling process, failing with ENAMETOOLONG if it is longer than max bytes.
+// The caveats of ReadMemory apply.
+func (r *Request) ReadString(addr uint64, max int) (string, error) {
+	f, err := r.openMem()
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	s, err := readString(f, addr, max)
+	if err != nil {
+		return "", err
+	}
+	return s, r.Valid()
+}
+
+// ReadPath reads the path pointed to by the argument arg of the system call.
+// The caveats of ReadMemory apply.
+func (r *Request) ReadPath(arg int) (string, error) {
+	return r.ReadString(r.Data.Args[arg], unix.PathMax-1)
+}
+
+// readString reads the NUL-terminated string at addr in mem, of at most max
+// bytes. It reads a page at most at a time, so as not to fail reading past
+// the end of the mapping of a string.
+func readString(mem io.ReaderAt, addr uint64, max int) (string, error) {
+	pageSize := uint64(os.Getpagesize())
+	var s []byte
+	for len(s) <= max {
+		chunk := make([]byte, pageSize-addr%pageSize)
+		n, err := mem.ReadAt(chunk, int64(addr))
+		if i := bytes.IndexByte(chunk[:n], 0); i >= 0 {
+			s = append(s, chunk[:i]...)
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+		s = append(s, chunk...)
+		addr += uint64(len(chunk))
+	}
+	if len(s) > max {
+		return "", unix.ENAMETOOLONG
+	}
+	return string(s), nil
+}
+
+// AddFd installs a duplicate of fd in the calling process, and returns its
+// number there. If target is not negative, it is used as the number,
+// replacing the fd with that number if any. If cloexec is true, the
+// close-on-exec flag of the installed fd is set. Requires Linux >= 5.9.
+func (r *Request) AddFd(fd, target int, cloexec bool) (int, error) {
+	return r.addFd(fd, target, cloexec, false)
+}
+
+func (r *Request) addFd(fd, target int, cloexec, send bool) (int, error) {
+	addfd := C.struct_seccomp_notif_addfd{
+		id:    C.__u64(r.ID),
+		srcfd: C.__u32(fd),
+	}
+	if target >= 0 {
+		addfd.flags |= C.C_ADDFD_FLAG_SETFD
+		addfd.newfd = C.__u32(target)
+	}
+	if send {
+		addfd.flags |= C.C_ADDFD_FLAG_SEND
+	}
+	if cloexec {
+		addfd.newfd_flags = C.__u32(unix.O_CLOEXEC)
+	}
+	for {
+		n, _, errno := unix.Syscall(unix.SYS_IOCTL, uintptr(r.Container.fd), uintptr(C.C_IOCTL_NOTIF_ADDFD), uintptr(unsafe.Pointer(&addfd)))
+		switch errno {
+		case 0:
+			return int(n), nil
+		case unix.EINTR:
+			continue
+		case unix.ENOENT:
+			return -1, ErrNotValid
+		default:
+			return -1, os.NewSyscallError("ioctl SECCOMP_IOCTL_NOTIF_ADDFD", errno)
+		}
+	}
+}
+
+// Response is the response to a notified system call. The zero value makes
+// the system call return 0.
+type Response struct {
+	// Val is the return value of the system call, if Error is 0.
+	Val uint64
+	// Error is the error the system call fails with, if not 0.
+	Error unix.Errno
+	// Continue makes the kernel execute the system call. Since the memory of
+	// the calling process can change after its arguments were read, it must
+	// not be used to allow a system call based on their values.
+	Continue bool
+
+	// sendFile is installed in the calling process, and its number returned.
+	sendFile *os.File
+	cloexec  bool
+}
+
+// Continue returns a response executing the system call.
+func Continue() Response {
+	return Response{Continue: true}
+}
+
+// Errno returns a response making the system call fail with err.
+func Errno(err unix.Errno) Response {
+	return Response{Error: err}
+}
+
+// Return returns a response making the system call return val.
+func Return(val uint64) Response {
+	return Response{Val: val}
+}
+
+// SendFile returns a response installing a duplicate of f in the calling
+// process, the system call returning its number. If cloexec is true, the
+// close-on-exec flag of the installed fd is set. f is closed once the
+// response is sent. Requires Linux >= 5.14.
+func SendFile(f *os.File, cloexec bool) Response {
+	return Response{sendFile: f, cloexec: cloexec}
+}
+
+func (r *Request) respond(resp Response) error {
+	if resp.sendFile != nil {
+		defer resp.sendFile.Close()
+		_, err := r.addFd(int(resp.sendFile.Fd()), -1, resp.cloexec, true)
+		return err
+	}
+	nresp := &libseccomp.ScmpNotifResp{
+		ID:    r.ID,
+		Error: int32(resp.Error),
+		Val:   resp.Val,
+	}
+	if resp.Continue {
+		nresp.Flags = libseccomp.NotifRespFlagContinue
+	}
+	if err := libseccomp.NotifRespond(r.Container.fd, nresp); err != nil {
+		if errors.Is(err, unix.ENOENT) {
+			return ErrNotValid
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+//go:build cgo && seccomp
+// +build cgo,seccomp
+
+package notify
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestReadString(t *testing.T) {
+	mem, err := os.Open("/proc/self/mem")
+	if err != nil {
+		t.Skip(err)
+	}
+	defer mem.Close()
+
+	// Put the string at the end of a page followed by an unmapped one, to
+	// check it's read without reading past the mapping.
+	pageSize := os.Getpagesize()
+	m, err := unix.Mmap(-1, 0, 2*pageSize, unix.PROT_READ|unix.PROT_WRITE, unix.MAP_PRIVATE|unix.MAP_ANONYMOUS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer unix.Munmap(m)
+	// unix.Munmap only unmaps whole mappings.
+	if _, _, errno := unix.Syscall(unix.SYS_MUNMAP, uintptr(unsafe.Pointer(&m[pageSize])), uintptr(pageSize), 0); errno != 0 {
+		t.Fatal(errno)
+	}
+	m = m[:pageSize]
+	const s = "/some/path"
+	copy(m[pageSize-len(s)-1:], s+"\x00")
+	addr := uint64(uintptr(unsafe.Pointer(&m[pageSize-len(s)-1])))
+
+	got, err := readString(mem, addr, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != s {
+		t.Errorf("got %q, want %q", got, s)
+	}
+	if _, err := readString(mem, addr, len(s)-1); !errors.Is(err, unix.ENAMETOOLONG) {
+		t.Errorf("expected ENAMETOOLONG, got %v", err)
+	}
+
+	// A string spanning pages.
+	long := strings.Repeat("a", pageSize+10) + "\x00"
+	buf := []byte(long)
+	got, err = readString(mem, uint64(uintptr(unsafe.Pointer(&buf[0]))), 2*pageSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != long[:len(long)-1] {
+		t.Errorf("got a string of %d bytes, want %d", len(got), len(long)-1)
+	}
+
+	// A string without NUL up to the end of the mapping.
+	for i := range m {
+		m[i] = 'b'
+	}
+	if _, err := readString(mem, uint64(uintptr(unsafe.Pointer(&m[0]))), 2*pageSize); err == nil {
+		t.Error("expected an error reading past the mapping")
+	}
+}
@@ -562,20 +562,37 @@ func generatePatch(config *configs.Seccomp) ([]bpf.Instruction, error) {
 	return stubProgram, nil
 }
 
-func enosysPatchFilter(config *configs.Seccomp, filter *libseccomp.ScmpFilter) ([]unix.SockFilter, error) {
-	program, err := disassembleFilter(filter)
+// Program returns the program PatchAndLoad loads for config and filter: the
+// -ENOSYS stub, if any, followed by the original filter. stubLen is the number
+// of instructions of the stub.
+func Program(config *configs.Seccomp, filter *libseccomp.ScmpFilter) (program []bpf.Instruction, stubLen int, err error) {
+	original, err := disassembleFilter(filter)
 	if err != nil {
-		return nil, fmt.Errorf("error disassembling original filter: %w", err)
+		return nil, 0, fmt.Errorf("error disassembling original filter: %w", err)
 	}
 
 	patch, err := generatePatch(config)
 	if err != nil {
-		return nil, fmt.Errorf("error generating patch for filter: %w", err)
+		return nil, 0, fmt.Errorf("error generating patch for filter: %w", err)
+	}
+	return append(patch, original...), len(patch), nil
+}
+
+// AuditArch returns the AUDIT_ARCH_* value of arch, which is the arch of the
+// seccomp data of its system calls.
+func AuditArch(arch libseccomp.ScmpArch) (uint32, error) {
+	native, err := archToNative(arch)
+	return uint32(native), err
+}
+
+func enosysPatchFilter(config *configs.Seccomp, filter *libseccomp.ScmpFilter) ([]unix.SockFilter, error) {
+	fullProgram, stubLen, err := Program(config, filter)
+	if err != nil {
+		return nil, err
 	}
-	fullProgram := append(patch, program...)
 
 	logrus.Debugf("seccomp: prepending -ENOSYS stub filter to user filter...")
-	for idx, insn := range patch {
+	for idx, insn := range fullProgram[:stubLen] {
 		logrus.Debugf("  [%4.1d] %s", idx, insn)
 	}
 	logrus.Debugf("  [....] --- original filter ---")
@@ -606,6 +623,9 @@ func filterFlags(config *configs.Seccomp, filter *libseccomp.ScmpFilter) (flags
 
 	// TODO: Support seccomp flags not yet added to libseccomp-golang...
 
+	if config.DefaultAction == configs.Notify {
+		flags |= uint(C.C_FILTER_FLAG_NEW_LISTENER)
+	}
 	for _, call := range config.Syscalls {
 		if call.Action == configs.Notify {
 			flags |= uint(C.C_FILTER_FLAG_NEW_LISTENER)
@@ -0,0 +1,36 @@
+package seccomp
+
+import (
+	"github.com/opencontainers/runc/libcontainer/configs"
+)
+
+// recordAllowed are the system calls allowed without being recorded, and
+// always allowed by the recorded profile. runc init may make them after
+// loading the filter and before handing the seccomp fd over to the
+// recorder, so they can't be notified.
+var recordAllowed = []string{
+	"exit", "exit_group", "futex", "getpid", "gettid", "madvise", "mmap",
+	"munmap", "nanosleep", "rt_sigprocmask", "rt_sigreturn", "sched_yield",
+	"sigaltstack", "tgkill", "write",
+}
+
+// RecordingProfile returns the profile container processes must use for
+// their system calls to be recorded by the Recorder listening at
+// listenerPath, on the given architectures (as in configs.Seccomp) in
+// addition to the native one. It is the only profile allowed to have a
+// SCMP_ACT_NOTIFY default action.
+func RecordingProfile(listenerPath string, archs []string) *configs.Seccomp {
+	profile := &configs.Seccomp{
+		DefaultAction: configs.Notify,
+		Architectures: archs,
+		ListenerPath:  listenerPath,
+		Recording:     true,
+	}
+	for _, name := range recordAllowed {
+		profile.Syscalls = append(profile.Syscalls, &configs.Syscall{
+			Name:   name,
+			Action: configs.Allow,
+		})
+	}
+	return profile
+}
@@ -0,0 +1,134 @@
+//go:build cgo && seccomp
+// +build cgo,seccomp
+
+package seccomp
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"sync"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+	libseccomp "github.com/seccomp/libseccomp-golang"
+	"github.com/sirupsen/logrus"
+
+	"github.com/opencontainers/runc/libcontainer/seccomp/notify"
+)
+
+// Recorder records the system calls made by container processes, to
+// generate a seccomp profile allowing them. The processes must use the
+// profile returned by RecordingProfile, notifying all their system calls to
+// the listener socket of the recorder, which lets them continue.
+type Recorder struct {
+	agent *notify.Agent
+
+	mu sync.Mutex
+	// syscalls are the names of the recorded system calls, by architecture.
+	syscalls map[specs.Arch]map[string]struct{}
+}
+
+// NewRecorder returns a Recorder listening on a unix socket at
+// listenerPath.
+func NewRecorder(listenerPath string) (*Recorder, error) {
+	// Ignore the error since pre-2.4 libseccomp is treated as API level 0.
+	if apiLevel, _ := libseccomp.GetAPI(); apiLevel < 6 {
+		return nil, fmt.Errorf("seccomp notify unsupported: API level: got %d, want at least 6. Please try with libseccomp >= 2.5.0 and Linux >= 5.7", apiLevel)
+	}
+	l, err := net.Listen("unix", listenerPath)
+	if err != nil {
+		return nil, err
+	}
+	r := &Recorder{
+		agent:    notify.NewAgent(),
+		syscalls: make(map[specs.Arch]map[string]struct{}),
+	}
+	r.agent.Default = r.record
+	go func() {
+		if err := r.agent.Serve(l); !errors.Is(err, notify.ErrAgentClosed) {
+			logrus.Errorf("seccomp recorder: %v", err)
+		}
+	}()
+	return r, nil
+}
+
+func (r *Recorder) record(req *notify.Request) notify.Response {
+	if req.Name == "" {
+		logrus.Debugf("seccomp recorder: unknown syscall %d on %s ignored", req.Data.Syscall, req.Data.Arch)
+		return notify.Continue()
+	}
+	arch := specArch(req.Data.Arch)
+	if arch == "" {
+		logrus.Debugf("seccomp recorder: syscall %s on unknown arch %s ignored", req.Name, req.Data.Arch)
+		return notify.Continue()
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	names := r.syscalls[arch]
+	if names == nil {
+		names = make(map[string]struct{})
+		r.syscalls[arch] = names
+	}
+	names[req.Name] = struct{}{}
+	return notify.Continue()
+}
+
+// Close stops recording.
+func (r *Recorder) Close() error {
+	return r.agent.Close()
+}
+
+// Profiles returns a profile per architecture system calls were recorded
+// on, allowing exactly the system calls recorded on that architecture, along
+// with the ones always allowed while recording. The other system calls fail
+// with EPERM. The profile of the native architecture comes first.
+func (r *Recorder) Profiles() []*specs.LinuxSeccomp {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var native specs.Arch
+	if arch, err := libseccomp.GetNativeArch(); err == nil {
+		native = specArch(arch)
+	}
+	recorded := make([]specs.Arch, 0, len(r.syscalls))
+	for arch := range r.syscalls {
+		recorded = append(recorded, arch)
+	}
+	sort.Slice(recorded, func(i, j int) bool {
+		if (recorded[i] == native) != (recorded[j] == native) {
+			return recorded[i] == native
+		}
+		return recorded[i] < recorded[j]
+	})
+	profiles := make([]*specs.LinuxSeccomp, 0, len(recorded))
+	for _, arch := range recorded {
+		seen := make(map[string]struct{})
+		for _, name := range recordAllowed {
+			seen[name] = struct{}{}
+		}
+		for name := range r.syscalls[arch] {
+			seen[name] = struct{}{}
+		}
+		names := make([]string, 0, len(seen))
+		for name := range seen {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		profiles = append(profiles, &specs.LinuxSeccomp{
+			DefaultAction: specs.ActErrno,
+			Architectures: []specs.Arch{arch},
+			Syscalls:      []specs.LinuxSyscall{{Names: names, Action: specs.ActAllow}},
+		})
+	}
+	return profiles
+}
+
+// specArch returns the runtime spec name of arch, or "" if it is unknown.
+func specArch(arch libseccomp.ScmpArch) specs.Arch {
+	for k, v := range archs {
+		if v == arch.String() {
+			return specs.Arch(k)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,79 @@
+//go:build cgo && seccomp
+// +build cgo,seccomp
+
+package seccomp
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
+	libseccomp "github.com/seccomp/libseccomp-golang"
+
+	"github.com/opencontainers/runc/libcontainer/configs"
+)
+
+func TestNotifyDefaultAction(t *testing.T) {
+	if apiLevel, _ := libseccomp.GetAPI(); apiLevel < 6 {
+		t.Skipf("seccomp notify requires API level 6, got %d", apiLevel)
+	}
+	// A user profile can't notify all the system calls, even when write
+	// is allowed.
+	config := &configs.Seccomp{
+		DefaultAction: configs.Notify,
+		ListenerPath:  "/run/agent.sock",
+		Syscalls:      []*configs.Syscall{{Name: "write", Action: configs.Allow}},
+	}
+	if _, err := buildFilter(config); err == nil {
+		t.Fatal("expected SCMP_ACT_NOTIFY default action to be rejected")
+	}
+	if _, err := buildFilter(RecordingProfile("/run/record.sock", nil)); err != nil {
+		t.Fatalf("recording profile: %v", err)
+	}
+}
+
+func TestRecorderProfiles(t *testing.T) {
+	native, err := libseccomp.GetNativeArch()
+	if err != nil {
+		t.Fatal(err)
+	}
+	nativeArch := specArch(native)
+	otherArch := specs.ArchX86
+	if nativeArch == otherArch {
+		otherArch = specs.ArchX86_64
+	}
+	r := &Recorder{syscalls: map[specs.Arch]map[string]struct{}{
+		otherArch:  {"socketcall": {}},
+		nativeArch: {"openat": {}},
+	}}
+	profiles := r.Profiles()
+	if len(profiles) != 2 {
+		t.Fatalf("expected 2 profiles, got %d", len(profiles))
+	}
+	for i, want := range []struct {
+		arch specs.Arch
+		name string
+	}{{nativeArch, "openat"}, {otherArch, "socketcall"}} {
+		p := profiles[i]
+		if !reflect.DeepEqual(p.Architectures, []specs.Arch{want.arch}) {
+			t.Errorf("profile %d: expected architectures [%s], got %v", i, want.arch, p.Architectures)
+		}
+		names := p.Syscalls[0].Names
+		has := func(name string) bool {
+			for _, n := range names {
+				if n == name {
+					return true
+				}
+			}
+			return false
+		}
+		if !has(want.name) || !has("write") {
+			t.Errorf("profile %d: expected %s and write to be allowed, got %v", i, want.name, names)
+		}
+		for _, other := range []string{"openat", "socketcall"} {
+			if other != want.name && has(other) {
+				t.Errorf("profile %d: %s was not recorded on %s, got %v", i, other, want.arch, names)
+			}
+		}
+	}
+}
@@ -5,6 +5,9 @@ package seccomp
 
 import (
 	"errors"
+	"io"
+
+	"github.com/opencontainers/runtime-spec/specs-go"
 
 	"github.com/opencontainers/runc/libcontainer/configs"
 )
@@ -19,6 +22,25 @@ func InitSeccomp(config *configs.Seccomp) (int, error) {
 	return -1, nil
 }
 
+// Recorder records the system calls made by container processes. It is
+// not supported without seccomp.
+type Recorder struct{}
+
+// NewRecorder returns an error because seccomp is not supported.
+func NewRecorder(listenerPath string) (*Recorder, error) {
+	return nil, ErrSeccompNotEnabled
+}
+
+// Close does nothing because seccomp is not supported.
+func (r *Recorder) Close() error {
+	return nil
+}
+
+// Profiles returns nil because seccomp is not supported.
+func (r *Recorder) Profiles() []*specs.LinuxSeccomp {
+	return nil
+}
+
 // Version returns major, minor, and micro.
 func Version() (uint, uint, uint) {
 	return 0, 0, 0
@@ -26,3 +48,21 @@ func Version() (uint, uint, uint) {
 
 // Enabled is true if seccomp support is compiled in.
 const Enabled = false
+
+// Program is a seccomp BPF program. It is not supported without seccomp.
+type Program struct{}
+
+// Compile returns an error because seccomp is not supported.
+func Compile(config *configs.Seccomp) (*Program, error) {
+	return nil, ErrSeccompNotEnabled
+}
+
+// Eval returns an error because seccomp is not supported.
+func (p *Program) Eval(call *Call) (Action, error) {
+	return 0, ErrSeccompNotEnabled
+}
+
+// Dump returns an error because seccomp is not supported.
+func (p *Program) Dump(w io.Writer) error {
+	return ErrSeccompNotEnabled
+}
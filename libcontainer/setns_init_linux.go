@@ -12,6 +12,7 @@ import (
 
 	"github.com/opencontainers/runc/libcontainer/apparmor"
 	"github.com/opencontainers/runc/libcontainer/keys"
+	"github.com/opencontainers/runc/libcontainer/landlock"
 	"github.com/opencontainers/runc/libcontainer/seccomp"
 	"github.com/opencontainers/runc/libcontainer/system"
 )
@@ -59,6 +60,21 @@ func (l *linuxSetnsInit) Init() error {
 			return err
 		}
 	}
+	if l.config.Scheduler != nil {
+		if err := setupScheduler(l.config.Scheduler); err != nil {
+			return err
+		}
+	}
+	if l.config.IOPriority != nil {
+		if err := setupIOPriority(l.config.IOPriority); err != nil {
+			return err
+		}
+	}
+	if l.config.Config.Personality != nil {
+		if err := setupPersonality(l.config.Config); err != nil {
+			return err
+		}
+	}
 	if err := selinux.SetExecLabel(l.config.ProcessLabel); err != nil {
 		return err
 	}
@@ -67,7 +83,7 @@ func (l *linuxSetnsInit) Init() error {
 	// do this before dropping capabilities; otherwise do it as late as possible
 	// just before execve so as few syscalls take place after it as possible.
 	if l.config.Config.Seccomp != nil && !l.config.NoNewPrivileges {
-		seccompFd, err := seccomp.InitSeccomp(l.config.Config.Seccomp)
+		seccompFd, err := seccomp.InitSeccomp(seccompConfig(l.config))
 		if err != nil {
 			return err
 		}
@@ -82,11 +98,18 @@ func (l *linuxSetnsInit) Init() error {
 	if err := apparmor.ApplyProfile(l.config.AppArmorProfile); err != nil {
 		return err
 	}
+	// Prepare the Landlock ruleset before the seccomp filter is loaded, so
+	// that only landlock_restrict_self(2) is left to do right before exec.
+	ruleset, err := landlock.Prepare(l.config.Landlock)
+	if err != nil {
+		return fmt.Errorf("unable to prepare landlock ruleset: %w", err)
+	}
+	defer ruleset.Close()
 	// Set seccomp as close to execve as possible, so as few syscalls take
 	// place afterward (reducing the amount of syscalls that users need to
 	// enable in their seccomp profiles).
 	if l.config.Config.Seccomp != nil && l.config.NoNewPrivileges {
-		seccompFd, err := seccomp.InitSeccomp(l.config.Config.Seccomp)
+		seccompFd, err := seccomp.InitSeccomp(seccompConfig(l.config))
 		if err != nil {
 			return fmt.Errorf("unable to init seccomp: %w", err)
 		}
@@ -101,5 +124,8 @@ func (l *linuxSetnsInit) Init() error {
 		return &os.PathError{Op: "close log pipe", Path: "fd " + strconv.Itoa(l.logFd), Err: err}
 	}
 
+	if err := ruleset.Enforce(); err != nil {
+		return err
+	}
 	return system.Execv(l.config.Args[0], l.config.Args[0:], os.Environ())
 }
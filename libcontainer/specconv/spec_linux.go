@@ -3,11 +3,13 @@
 package specconv
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -37,6 +39,7 @@ var (
 		clear bool
 		flag  int
 	}
+	idmapFlags map[string]bool
 )
 
 func initMaps() {
@@ -49,6 +52,7 @@ func initMaps() {
 			specs.IPCNamespace:     configs.NEWIPC,
 			specs.UTSNamespace:     configs.NEWUTS,
 			specs.CgroupNamespace:  configs.NEWCGROUP,
+			specs.TimeNamespace:    configs.NEWTIME,
 		}
 
 		mountPropagationMapping = map[string]int{
@@ -125,7 +129,7 @@ func initMaps() {
 			"rnostrictatime": {true, unix.MOUNT_ATTR_STRICTATIME},
 			"rnosymfollow":   {false, unix.MOUNT_ATTR_NOSYMFOLLOW}, // since kernel 5.14
 			"rsymfollow":     {true, unix.MOUNT_ATTR_NOSYMFOLLOW},  // since kernel 5.14
-			// No support for MOUNT_ATTR_IDMAP yet (needs UserNS FD)
+			// MOUNT_ATTR_IDMAP is handled by idmapFlags (needs UserNS FD)
 		}
 
 		extensionFlags = map[string]struct {
@@ -134,6 +138,12 @@ func initMaps() {
 		}{
 			"tmpcopyup": {false, configs.EXT_COPYUP},
 		}
+
+		// The value tells whether the mapping is recursive.
+		idmapFlags = map[string]bool{
+			"idmap":  false, // since kernel 5.12
+			"ridmap": true,  // since kernel 5.12
+		}
 	})
 }
 
@@ -166,6 +176,9 @@ func KnownMountOptions() []string {
 	for k := range extensionFlags {
 		res = append(res, k)
 	}
+	for k := range idmapFlags {
+		res = append(res, k)
+	}
 	sort.Strings(res)
 	return res
 }
@@ -418,6 +431,7 @@ func CreateLibcontainerConfig(opts *CreateOpts) (*configs.Config, error) {
 		config.ReadonlyPaths = spec.Linux.ReadonlyPaths
 		config.MountLabel = spec.Linux.MountLabel
 		config.Sysctl = spec.Linux.Sysctl
+		config.TimeOffsets = spec.Linux.TimeOffsets
 		if spec.Linux.Seccomp != nil {
 			seccomp, err := SetupSeccomp(spec.Linux.Seccomp)
 			if err != nil {
@@ -425,6 +439,13 @@ func CreateLibcontainerConfig(opts *CreateOpts) (*configs.Config, error) {
 			}
 			config.Seccomp = seccomp
 		}
+		if spec.Linux.Personality != nil {
+			p, err := convertPersonality(spec.Linux.Personality)
+			if err != nil {
+				return nil, err
+			}
+			config.Personality = p
+		}
 		if spec.Linux.IntelRdt != nil {
 			config.IntelRdt = &configs.IntelRdt{
 				ClosID:        spec.Linux.IntelRdt.ClosID,
@@ -481,6 +502,9 @@ func CreateLibcontainerConfig(opts *CreateOpts) (*configs.Config, error) {
 		config.NoNewPrivileges = spec.Process.NoNewPrivileges
 		config.Umask = spec.Process.User.Umask
 		config.ProcessLabel = spec.Process.SelinuxLabel
+		config.Scheduler = configs.NewScheduler(spec.Process.Scheduler)
+		config.IOPriority = spec.Process.IOPriority
+		config.ExecCPUAffinity = spec.Process.ExecCPUAffinity
 		if spec.Process.Capabilities != nil {
 			config.Capabilities = &configs.Capabilities{
 				Bounding:    spec.Process.Capabilities.Bounding,
@@ -491,11 +515,107 @@ func CreateLibcontainerConfig(opts *CreateOpts) (*configs.Config, error) {
 			}
 		}
 	}
+	if v, ok := spec.Annotations[landlockAnnotation]; ok {
+		l, err := parseLandlockAnnotation(v)
+		if err != nil {
+			return nil, fmt.Errorf("annotation %s value parse error: %w", landlockAnnotation, err)
+		}
+		config.Landlock = l
+	}
+	if err := setSchedUtilClamp(config.Scheduler, spec.Annotations); err != nil {
+		return nil, err
+	}
 	createHooks(spec, config)
 	config.Version = specs.Version
 	return config, nil
 }
 
+func convertPersonality(p *specs.LinuxPersonality) (*configs.LinuxPersonality, error) {
+	var domain int
+	switch p.Domain {
+	case specs.PerLinux:
+		domain = configs.PerLinux
+	case specs.PerLinux32:
+		domain = configs.PerLinux32
+	default:
+		return nil, fmt.Errorf("invalid personality domain %q", p.Domain)
+	}
+	// No personality flags are currently defined by the runtime spec.
+	if len(p.Flags) > 0 {
+		return nil, fmt.Errorf("invalid personality flags %q", p.Flags)
+	}
+	return &configs.LinuxPersonality{Domain: domain}, nil
+}
+
+// schedUtilMinAnnotation and schedUtilMaxAnnotation are the annotations
+// holding the utilization clamp values of the container scheduler, as there
+// are no such fields in the runtime spec.
+const (
+	schedUtilMinAnnotation = "org.opencontainers.runc.sched.util_min"
+	schedUtilMaxAnnotation = "org.opencontainers.runc.sched.util_max"
+)
+
+// setSchedUtilClamp sets the utilization clamp values of the scheduler from
+// the annotations.
+func setSchedUtilClamp(s *configs.Scheduler, annotations map[string]string) error {
+	for _, a := range []string{schedUtilMinAnnotation, schedUtilMaxAnnotation} {
+		v, ok := annotations[a]
+		if !ok {
+			continue
+		}
+		if s == nil {
+			return fmt.Errorf("annotation %s requires process.scheduler to be set", a)
+		}
+		util, err := strconv.ParseUint(v, 10, 32)
+		if err != nil {
+			return fmt.Errorf("annotation %s value parse error: %w", a, err)
+		}
+		if a == schedUtilMinAnnotation {
+			s.UtilMin = uint32(util)
+		} else {
+			s.UtilMax = uint32(util)
+		}
+	}
+	return nil
+}
+
+// landlockAnnotation is the annotation holding the Landlock ruleset of the
+// container, as there is no such field in the runtime spec.
+const landlockAnnotation = "org.opencontainers.runc.landlock"
+
+// parseLandlockAnnotation parses the Landlock ruleset from its JSON
+// representation, e.g.
+//
+//	{"handledAccessFS": ["read_file", "write_file"],
+//	 "rules": [{"paths": ["/usr"], "allowedAccess": ["read_file"]}],
+//	 "abi": 1}
+func parseLandlockAnnotation(value string) (*configs.Landlock, error) {
+	var l struct {
+		HandledAccessFS []string `json:"handledAccessFS"`
+		Rules           []struct {
+			Paths         []string `json:"paths"`
+			AllowedAccess []string `json:"allowedAccess"`
+		} `json:"rules"`
+		ABI int `json:"abi"`
+	}
+	dec := json.NewDecoder(strings.NewReader(value))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&l); err != nil {
+		return nil, err
+	}
+	ll := &configs.Landlock{
+		HandledAccessFS: l.HandledAccessFS,
+		ABI:             l.ABI,
+	}
+	for _, r := range l.Rules {
+		ll.Rules = append(ll.Rules, configs.LandlockRule{
+			Paths:         r.Paths,
+			AllowedAccess: r.AllowedAccess,
+		})
+	}
+	return ll, nil
+}
+
 func createLibcontainerMount(cwd string, m specs.Mount) (*configs.Mount, error) {
 	if !filepath.IsAbs(m.Destination) {
 		// Relax validation for backward compatibility
@@ -518,6 +638,18 @@ func createLibcontainerMount(cwd string, m specs.Mount) (*configs.Mount, error)
 		}
 	}
 
+	if len(m.UIDMappings) > 0 || len(m.GIDMappings) > 0 {
+		if mnt.IDMapping == nil {
+			// Neither "idmap" nor "ridmap" were specified, so
+			// follow the recursiveness of the bind mount.
+			mnt.IDMapping = &configs.MountIDMapping{
+				Recursive: mnt.Flags&unix.MS_REC != 0,
+			}
+		}
+		mnt.IDMapping.UIDMappings = toConfigIDMap(m.UIDMappings)
+		mnt.IDMapping.GIDMappings = toConfigIDMap(m.GIDMappings)
+	}
+
 	// None of the mount arguments can contain a null byte. Normally such
 	// strings would either cause some other failure or would just be truncated
 	// when we hit the null byte, but because we serialise these strings as
@@ -829,6 +961,9 @@ func CreateCgroupConfig(opts *CreateOpts, defaultDevs []*devices.Device) (*confi
 				for k, v := range r.Unified {
 					c.Resources.Unified[k] = v
 				}
+				if err := convertUnifiedMemory(c.Resources); err != nil {
+					return nil, err
+				}
 			}
 		}
 	}
@@ -840,6 +975,46 @@ func CreateCgroupConfig(opts *CreateOpts, defaultDevs []*devices.Device) (*confi
 	return c, nil
 }
 
+// convertUnifiedMemory moves the memory settings that have dedicated
+// fields in configs.Resources out of the unified map, as the runtime spec
+// has no other way to specify them.
+func convertUnifiedMemory(r *configs.Resources) error {
+	for k, dest := range map[string]**int64{
+		"memory.high":      &r.MemoryHigh,
+		"memory.min":       &r.MemoryMin,
+		"memory.swap.high": &r.MemorySwapHigh,
+		"memory.zswap.max": &r.MemoryZswapMax,
+	} {
+		v, ok := r.Unified[k]
+		if !ok {
+			continue
+		}
+		num := int64(-1)
+		if v = strings.TrimSpace(v); v != "max" {
+			var err error
+			num, err = strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				return fmt.Errorf("unified resource %q value conversion error: %w", k, err)
+			}
+		}
+		*dest = &num
+		delete(r.Unified, k)
+	}
+	if v, ok := r.Unified["memory.oom.group"]; ok {
+		var group bool
+		switch strings.TrimSpace(v) {
+		case "0":
+		case "1":
+			group = true
+		default:
+			return fmt.Errorf("unified resource %q value invalid: %q", "memory.oom.group", v)
+		}
+		r.MemoryOOMGroup = &group
+		delete(r.Unified, "memory.oom.group")
+	}
+	return nil
+}
+
 func stringToCgroupDeviceRune(s string) (devices.Type, error) {
 	switch s {
 	case "a":
@@ -923,20 +1098,31 @@ next:
 	return dedupedAllowDevs, nil
 }
 
-func setupUserNamespace(spec *specs.Spec, config *configs.Config) error {
-	create := func(m specs.LinuxIDMapping) configs.IDMap {
-		return configs.IDMap{
+func toConfigIDMap(specMaps []specs.LinuxIDMapping) []configs.IDMap {
+	if specMaps == nil {
+		return nil
+	}
+	idmaps := make([]configs.IDMap, len(specMaps))
+	for i, m := range specMaps {
+		idmaps[i] = configs.IDMap{
 			HostID:      int(m.HostID),
 			ContainerID: int(m.ContainerID),
 			Size:        int(m.Size),
 		}
 	}
+	return idmaps
+}
+
+func setupUserNamespace(spec *specs.Spec, config *configs.Config) error {
 	if spec.Linux != nil {
-		for _, m := range spec.Linux.UIDMappings {
-			config.UidMappings = append(config.UidMappings, create(m))
-		}
-		for _, m := range spec.Linux.GIDMappings {
-			config.GidMappings = append(config.GidMappings, create(m))
+		config.UidMappings = toConfigIDMap(spec.Linux.UIDMappings)
+		config.GidMappings = toConfigIDMap(spec.Linux.GIDMappings)
+	}
+	// ID-mapped mounts without explicit mappings use the container's ones.
+	for _, m := range config.Mounts {
+		if m.IsIDMapped() && m.IDMapping.UIDMappings == nil && m.IDMapping.GIDMappings == nil {
+			m.IDMapping.UIDMappings = config.UidMappings
+			m.IDMapping.GIDMappings = config.GidMappings
 		}
 	}
 	rootUID, err := config.HostRootUID()
@@ -992,6 +1178,8 @@ func parseMountOptions(options []string) *configs.Mount {
 			} else {
 				m.Extensions |= f.flag
 			}
+		} else if recursive, exists := idmapFlags[o]; exists {
+			m.IDMapping = &configs.MountIDMapping{Recursive: recursive}
 		} else {
 			data = append(data, o)
 		}
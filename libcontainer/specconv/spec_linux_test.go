@@ -2,6 +2,7 @@ package specconv
 
 import (
 	"os"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -390,6 +391,57 @@ func TestLinuxCgroupWithMemoryResource(t *testing.T) {
 	}
 }
 
+func TestLinuxCgroupWithUnifiedMemory(t *testing.T) {
+	spec := &specs.Spec{
+		Linux: &specs.Linux{
+			CgroupsPath: "/user/cgroups/path/id",
+			Resources: &specs.LinuxResources{
+				Unified: map[string]string{
+					"memory.high":      "1000000",
+					"memory.min":       "0",
+					"memory.swap.high": "max",
+					"memory.oom.group": "1",
+					"pids.max":         "100",
+				},
+			},
+		},
+	}
+
+	cgroup, err := CreateCgroupConfig(&CreateOpts{CgroupName: "ContainerID", Spec: spec}, nil)
+	if err != nil {
+		t.Fatalf("Couldn't create Cgroup config: %v", err)
+	}
+
+	r := cgroup.Resources
+	if r.MemoryHigh == nil || *r.MemoryHigh != 1000000 {
+		t.Errorf("Expected memory high to be 1000000, got %v", r.MemoryHigh)
+	}
+	if r.MemoryMin == nil || *r.MemoryMin != 0 {
+		t.Errorf("Expected memory min to be 0, got %v", r.MemoryMin)
+	}
+	if r.MemorySwapHigh == nil || *r.MemorySwapHigh != -1 {
+		t.Errorf("Expected swap high to be -1, got %v", r.MemorySwapHigh)
+	}
+	if r.MemoryZswapMax != nil {
+		t.Errorf("Expected zswap max to be unset, got %d", *r.MemoryZswapMax)
+	}
+	if r.MemoryOOMGroup == nil || !*r.MemoryOOMGroup {
+		t.Errorf("Expected memory oom group to be set")
+	}
+	if len(r.Unified) != 1 || r.Unified["pids.max"] != "100" {
+		t.Errorf("Expected only pids.max to be left in unified, got %v", r.Unified)
+	}
+	// The spec must not be modified.
+	if len(spec.Linux.Resources.Unified) != 5 {
+		t.Errorf("Expected spec unified map to be left intact, got %v", spec.Linux.Resources.Unified)
+	}
+
+	spec.Linux.Resources.Unified = map[string]string{"memory.high": "1G"}
+	if _, err := CreateCgroupConfig(&CreateOpts{CgroupName: "ContainerID", Spec: spec}, nil); err == nil {
+		t.Error("Expected error for invalid memory.high value, got nil")
+	}
+}
+
 func TestLinuxCgroupSystemd(t *testing.T) {
 	cgroupsPath := "parent:scopeprefix:name"
 
@@ -545,6 +597,119 @@ func TestSpecconvExampleValidate(t *testing.T) {
 	}
 }
 
+func TestSpecconvLandlockAnnotation(t *testing.T) {
+	spec := Example()
+	spec.Root.Path = "/"
+	spec.Annotations = map[string]string{
+		"org.opencontainers.runc.landlock": `{"handledAccessFS": ["read_file", "write_file"], "rules": [{"paths": ["/usr", "/etc"], "allowedAccess": ["read_file"]}], "abi": 1}`,
+	}
+
+	config, err := CreateLibcontainerConfig(&CreateOpts{CgroupName: "ContainerID", Spec: spec})
+	if err != nil {
+		t.Fatalf("Couldn't create libcontainer config: %v", err)
+	}
+
+	expected := &configs.Landlock{
+		HandledAccessFS: []string{"read_file", "write_file"},
+		Rules: []configs.LandlockRule{
+			{Paths: []string{"/usr", "/etc"}, AllowedAccess: []string{"read_file"}},
+		},
+		ABI: 1,
+	}
+	if !reflect.DeepEqual(config.Landlock, expected) {
+		t.Errorf("Expected landlock %+v, got %+v", expected, config.Landlock)
+	}
+
+	spec.Annotations["org.opencontainers.runc.landlock"] = `{"handledAccess": ["read_file"]}`
+	if _, err := CreateLibcontainerConfig(&CreateOpts{CgroupName: "ContainerID", Spec: spec}); err == nil {
+		t.Error("Expected an error for an invalid landlock annotation")
+	}
+}
+
+func TestSpecconvSchedUtilClamp(t *testing.T) {
+	spec := Example()
+	spec.Root.Path = "/"
+	spec.Annotations = map[string]string{
+		"org.opencontainers.runc.sched.util_min": "256",
+		"org.opencontainers.runc.sched.util_max": "512",
+	}
+
+	if _, err := CreateLibcontainerConfig(&CreateOpts{CgroupName: "ContainerID", Spec: spec}); err == nil {
+		t.Error("Expected an error for utilization clamp values without a scheduler")
+	}
+
+	spec.Process.Scheduler = &specs.Scheduler{
+		Policy: specs.SchedOther,
+		Flags:  []specs.LinuxSchedulerFlag{specs.SchedFlagUtilClampMin, specs.SchedFlagUtilClampMax},
+	}
+	config, err := CreateLibcontainerConfig(&CreateOpts{CgroupName: "ContainerID", Spec: spec})
+	if err != nil {
+		t.Fatalf("Couldn't create libcontainer config: %v", err)
+	}
+
+	expected := &configs.Scheduler{
+		Policy:  specs.SchedOther,
+		Flags:   []specs.LinuxSchedulerFlag{specs.SchedFlagUtilClampMin, specs.SchedFlagUtilClampMax},
+		UtilMin: 256,
+		UtilMax: 512,
+	}
+	if !reflect.DeepEqual(config.Scheduler, expected) {
+		t.Errorf("Expected scheduler %+v, got %+v", expected, config.Scheduler)
+	}
+	attr, err := configs.ToSchedAttr(config.Scheduler)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if attr.Flags != 0x60 || attr.UtilMin != 256 || attr.UtilMax != 512 {
+		t.Errorf("Expected the utilization clamp flags and values, got %+v", attr)
+	}
+
+	spec.Annotations["org.opencontainers.runc.sched.util_max"] = "max"
+	if _, err := CreateLibcontainerConfig(&CreateOpts{CgroupName: "ContainerID", Spec: spec}); err == nil {
+		t.Error("Expected an error for an invalid utilization clamp annotation")
+	}
+}
+
+func TestSpecconvPersonality(t *testing.T) {
+	spec := Example()
+	spec.Root.Path = "/"
+	spec.Linux.Personality = &specs.LinuxPersonality{Domain: specs.PerLinux32}
+
+	config, err := CreateLibcontainerConfig(&CreateOpts{CgroupName: "ContainerID", Spec: spec})
+	if err != nil {
+		t.Fatalf("Couldn't create libcontainer config: %v", err)
+	}
+	if config.Personality == nil || config.Personality.Domain != configs.PerLinux32 {
+		t.Errorf("Expected LINUX32 personality, got %+v", config.Personality)
+	}
+
+	spec.Linux.Personality = &specs.LinuxPersonality{Domain: "LINUX16"}
+	if _, err := CreateLibcontainerConfig(&CreateOpts{CgroupName: "ContainerID", Spec: spec}); err == nil {
+		t.Error("Expected an error for an unsupported personality domain")
+	}
+}
+
+func TestSpecconvTimeOffsets(t *testing.T) {
+	spec := Example()
+	spec.Root.Path = "/"
+	spec.Linux.Namespaces = append(spec.Linux.Namespaces, specs.LinuxNamespace{Type: specs.TimeNamespace})
+	spec.Linux.TimeOffsets = map[string]specs.LinuxTimeOffset{
+		"monotonic": {Secs: 1337, Nanosecs: 3141519},
+		"boottime":  {Secs: -7},
+	}
+
+	config, err := CreateLibcontainerConfig(&CreateOpts{CgroupName: "ContainerID", Spec: spec})
+	if err != nil {
+		t.Fatalf("Couldn't create libcontainer config: %v", err)
+	}
+	if !config.Namespaces.Contains(configs.NEWTIME) {
+		t.Error("Expected a time namespace")
+	}
+	if !reflect.DeepEqual(config.TimeOffsets, spec.Linux.TimeOffsets) {
+		t.Errorf("Expected time offsets %+v, got %+v", spec.Linux.TimeOffsets, config.TimeOffsets)
+	}
+}
+
 func TestSpecconvNoLinuxSection(t *testing.T) {
 	spec := Example()
 	spec.Root.Path = "/"
@@ -792,6 +957,65 @@ func BenchmarkCheckPropertyName(b *testing.B) {
 	}
 }
 
+func TestIDMappedMounts(t *testing.T) {
+	spec := Example()
+	spec.Mounts = append(spec.Mounts, specs.Mount{
+		Destination: "/data",
+		Source:      "/srv/data",
+		Options:     []string{"rbind", "ridmap"},
+		UIDMappings: []specs.LinuxIDMapping{{ContainerID: 0, HostID: 1000, Size: 1}},
+		GIDMappings: []specs.LinuxIDMapping{{ContainerID: 0, HostID: 2000, Size: 1}},
+	}, specs.Mount{
+		Destination: "/shared",
+		Source:      "/srv/shared",
+		Options:     []string{"bind", "idmap"},
+	})
+	spec.Linux.Namespaces = append(spec.Linux.Namespaces, specs.LinuxNamespace{Type: specs.UserNamespace})
+	spec.Linux.UIDMappings = []specs.LinuxIDMapping{{ContainerID: 0, HostID: 100000, Size: 65536}}
+	spec.Linux.GIDMappings = []specs.LinuxIDMapping{{ContainerID: 0, HostID: 100000, Size: 65536}}
+
+	config, err := CreateLibcontainerConfig(&CreateOpts{
+		CgroupName: "ContainerID",
+		Spec:       spec,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var data, shared *configs.Mount
+	for _, m := range config.Mounts {
+		switch m.Destination {
+		case "/data":
+			data = m
+		case "/shared":
+			shared = m
+		}
+	}
+	if data == nil || !data.IsIDMapped() {
+		t.Fatalf("expected /data to be ID-mapped, got %+v", data)
+	}
+	if !data.IDMapping.Recursive {
+		t.Error("expected /data ID mapping to be recursive")
+	}
+	if len(data.IDMapping.UIDMappings) != 1 || data.IDMapping.UIDMappings[0].HostID != 1000 {
+		t.Errorf("unexpected /data uid mappings: %+v", data.IDMapping.UIDMappings)
+	}
+	if len(data.IDMapping.GIDMappings) != 1 || data.IDMapping.GIDMappings[0].HostID != 2000 {
+		t.Errorf("unexpected /data gid mappings: %+v", data.IDMapping.GIDMappings)
+	}
+
+	if shared == nil || !shared.IsIDMapped() {
+		t.Fatalf("expected /shared to be ID-mapped, got %+v", shared)
+	}
+	if shared.IDMapping.Recursive {
+		t.Error("expected /shared ID mapping not to be recursive")
+	}
+	// Without explicit mappings, the container ones are used.
+	if len(shared.IDMapping.UIDMappings) != 1 || shared.IDMapping.UIDMappings[0].HostID != 100000 {
+		t.Errorf("unexpected /shared uid mappings: %+v", shared.IDMapping.UIDMappings)
+	}
+}
+
 func TestNullProcess(t *testing.T) {
 	spec := Example()
 	spec.Process = nil
@@ -15,6 +15,7 @@ import (
 	"github.com/opencontainers/runc/libcontainer/apparmor"
 	"github.com/opencontainers/runc/libcontainer/configs"
 	"github.com/opencontainers/runc/libcontainer/keys"
+	"github.com/opencontainers/runc/libcontainer/landlock"
 	"github.com/opencontainers/runc/libcontainer/seccomp"
 	"github.com/opencontainers/runc/libcontainer/system"
 )
@@ -25,7 +26,7 @@ type linuxStandardInit struct {
 	parentPid     int
 	fifoFd        int
 	logFd         int
-	mountFds      []int
+	mountFds      mountFds
 	config        *initConfig
 }
 
@@ -88,13 +89,15 @@ func (l *linuxStandardInit) Init() error {
 
 	// We don't need the mountFds after prepareRootfs() nor if it fails.
 	err := prepareRootfs(l.pipe, l.config, l.mountFds)
-	for _, m := range l.mountFds {
-		if m == -1 {
-			continue
-		}
+	for _, fds := range [][]int{l.mountFds.sourceFds, l.mountFds.idmapFds} {
+		for _, m := range fds {
+			if m == -1 {
+				continue
+			}
 
-		if err := unix.Close(m); err != nil {
-			return fmt.Errorf("Unable to close mountFds fds: %w", err)
+			if err := unix.Close(m); err != nil {
+				return fmt.Errorf("Unable to close mountFds fds: %w", err)
+			}
 		}
 	}
 
@@ -154,6 +157,21 @@ func (l *linuxStandardInit) Init() error {
 			return &os.SyscallError{Syscall: "prctl(SET_NO_NEW_PRIVS)", Err: err}
 		}
 	}
+	if l.config.Scheduler != nil {
+		if err := setupScheduler(l.config.Scheduler); err != nil {
+			return err
+		}
+	}
+	if l.config.IOPriority != nil {
+		if err := setupIOPriority(l.config.IOPriority); err != nil {
+			return err
+		}
+	}
+	if l.config.Config.Personality != nil {
+		if err := setupPersonality(l.config.Config); err != nil {
+			return err
+		}
+	}
 	// Tell our parent that we're ready to Execv. This must be done before the
 	// Seccomp rules have been applied, because we need to be able to read and
 	// write to a socket.
@@ -168,7 +186,7 @@ func (l *linuxStandardInit) Init() error {
 	// do this before dropping capabilities; otherwise do it as late as possible
 	// just before execve so as few syscalls take place after it as possible.
 	if l.config.Config.Seccomp != nil && !l.config.NoNewPrivileges {
-		seccompFd, err := seccomp.InitSeccomp(l.config.Config.Seccomp)
+		seccompFd, err := seccomp.InitSeccomp(seccompConfig(l.config))
 		if err != nil {
 			return err
 		}
@@ -198,13 +216,22 @@ func (l *linuxStandardInit) Init() error {
 	if err != nil {
 		return err
 	}
+	// Prepare the Landlock ruleset before the seccomp filter is loaded, so
+	// that only landlock_restrict_self(2) is left to do right before exec
+	// (the ruleset would otherwise prevent us from opening the exec fifo
+	// and running the startContainer hooks).
+	ruleset, err := landlock.Prepare(l.config.Landlock)
+	if err != nil {
+		return fmt.Errorf("unable to prepare landlock ruleset: %w", err)
+	}
+	defer ruleset.Close()
 	// Set seccomp as close to execve as possible, so as few syscalls take
 	// place afterward (reducing the amount of syscalls that users need to
 	// enable in their seccomp profiles). However, this needs to be done
 	// before closing the pipe since we need it to pass the seccompFd to
 	// the parent.
 	if l.config.Config.Seccomp != nil && l.config.NoNewPrivileges {
-		seccompFd, err := seccomp.InitSeccomp(l.config.Config.Seccomp)
+		seccompFd, err := seccomp.InitSeccomp(seccompConfig(l.config))
 		if err != nil {
 			return fmt.Errorf("unable to init seccomp: %w", err)
 		}
@@ -250,5 +277,8 @@ func (l *linuxStandardInit) Init() error {
 		return err
 	}
 
+	if err := ruleset.Enforce(); err != nil {
+		return err
+	}
 	return system.Exec(name, l.config.Args[0:], os.Environ())
 }
@@ -48,6 +48,9 @@ func destroy(c *linuxContainer) error {
 			err = ierr
 		}
 	}
+	if nerr := destroyNetwork(c.config); err == nil {
+		err = nerr
+	}
 	if rerr := os.RemoveAll(c.root); err == nil {
 		err = rerr
 	}
@@ -11,3 +11,28 @@ type Stats struct {
 	CgroupStats   *cgroups.Stats
 	IntelRdtStats *intelrdt.Stats
 }
+
+// StatsMask selects the statistics collected by Container.FillStats.
+type StatsMask uint64
+
+const (
+	StatsCPU     = StatsMask(cgroups.StatsCPU)
+	StatsCPUSet  = StatsMask(cgroups.StatsCPUSet)
+	StatsMemory  = StatsMask(cgroups.StatsMemory)
+	StatsPids    = StatsMask(cgroups.StatsPids)
+	StatsBlkio   = StatsMask(cgroups.StatsBlkio)
+	StatsHugetlb = StatsMask(cgroups.StatsHugetlb)
+	StatsRdma    = StatsMask(cgroups.StatsRdma)
+	// StatsIntelRdt selects the Intel RDT statistics.
+	StatsIntelRdt StatsMask = 1 << 32
+	// StatsNetwork selects the network interfaces statistics.
+	StatsNetwork StatsMask = 1 << 33
+
+	// StatsAll selects all the statistics.
+	StatsAll = StatsMask(cgroups.StatsAll) | StatsIntelRdt | StatsNetwork
+)
+
+// cgroupStats returns the cgroup controllers selected by m.
+func (m StatsMask) cgroupStats() cgroups.StatsMask {
+	return cgroups.StatsMask(m & StatsMask(cgroups.StatsAll))
+}
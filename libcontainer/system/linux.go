@@ -11,6 +11,12 @@ import (
 	"golang.org/x/sys/unix"
 )
 
+// Flags for open_tree(2) and move_mount(2), not yet provided by x/sys/unix.
+const (
+	OPEN_TREE_CLONE         = 0x1 //nolint:golint // ignore "don't use ALL_CAPS" warning
+	MOVE_MOUNT_F_EMPTY_PATH = 0x4 //nolint:golint // ignore "don't use ALL_CAPS" warning
+)
+
 type ParentDeathSignal int
 
 func (p ParentDeathSignal) Restore() error {
@@ -102,3 +108,168 @@ func GetSubreaper() (int, error) {
 
 	return int(i), nil
 }
+
+// PidfdSendSignal is a wrapper for pidfd_send_signal(2).
+func PidfdSendSignal(pidfd int, sig unix.Signal) error {
+	_, _, errno := unix.Syscall6(unix.SYS_PIDFD_SEND_SIGNAL, uintptr(pidfd), uintptr(sig), 0, 0, 0, 0)
+	if errno != 0 {
+		return &os.SyscallError{Syscall: "pidfd_send_signal", Err: errno}
+	}
+	return nil
+}
+
+// pidfdInfo is the first version of the pidfd_info structure used by the
+// PIDFD_GET_INFO ioctl, not yet provided by x/sys/unix.
+type pidfdInfo struct {
+	Mask     uint64
+	CgroupID uint64
+	Pid      uint32
+	Tgid     uint32
+	Ppid     uint32
+	Ruid     uint32
+	Rgid     uint32
+	Euid     uint32
+	Egid     uint32
+	Suid     uint32
+	Sgid     uint32
+	Fsuid    uint32
+	Fsgid    uint32
+	ExitCode int32
+}
+
+const (
+	pidfdGetInfo  = 0xc040ff0b // _IOWR(0xFF, 11, struct pidfd_info)
+	pidfdInfoExit = 0x8
+)
+
+// PidfdGetExitStatus returns the wait status of the exited process
+// referred to by pidfd, using the PIDFD_GET_INFO ioctl. The kernel records
+// it when the process is reaped, if a pidfd referring to the process is
+// open by then. The returned bool is false if the status is not (yet)
+// available, including on kernels older than Linux 6.15.
+func PidfdGetExitStatus(pidfd int) (unix.WaitStatus, bool, error) {
+	info := pidfdInfo{Mask: pidfdInfoExit}
+	_, _, errno := unix.Syscall(unix.SYS_IOCTL, uintptr(pidfd), pidfdGetInfo, uintptr(unsafe.Pointer(&info)))
+	if errno != 0 {
+		switch errno {
+		case unix.ENOTTY, unix.EINVAL, unix.ESRCH:
+			// Not supported, or the process is gone with no status.
+			return 0, false, nil
+		}
+		return 0, false, &os.SyscallError{Syscall: "ioctl(PIDFD_GET_INFO)", Err: errno}
+	}
+	if info.Mask&pidfdInfoExit == 0 {
+		return 0, false, nil
+	}
+	return unix.WaitStatus(info.ExitCode), true, nil
+}
+
+// SchedAttr is the sched_attr structure used by sched_setattr(2), not yet
+// provided by x/sys/unix.
+type SchedAttr struct {
+	Size     uint32
+	Policy   uint32
+	Flags    uint64
+	Nice     int32
+	Priority uint32
+	Runtime  uint64
+	Deadline uint64
+	Period   uint64
+	UtilMin  uint32
+	UtilMax  uint32
+}
+
+// SchedSetAttr is a wrapper for sched_setattr(2). The Size field of attr
+// is filled in by this function.
+func SchedSetAttr(pid int, attr *SchedAttr, flags uint) error {
+	attr.Size = uint32(unsafe.Sizeof(*attr))
+	_, _, errno := unix.Syscall(unix.SYS_SCHED_SETATTR, uintptr(pid), uintptr(unsafe.Pointer(attr)), uintptr(flags))
+	if errno != 0 {
+		return &os.SyscallError{Syscall: "sched_setattr", Err: errno}
+	}
+	return nil
+}
+
+// IOPRIO_WHO_PROCESS is the ioprio_set(2) target for a single process,
+// not yet provided by x/sys/unix.
+const IOPRIO_WHO_PROCESS = 1 //nolint:golint // ignore "don't use ALL_CAPS" warning
+
+// IoprioSet is a wrapper for ioprio_set(2).
+func IoprioSet(which, who, ioprio int) error {
+	_, _, errno := unix.Syscall(unix.SYS_IOPRIO_SET, uintptr(which), uintptr(who), uintptr(ioprio))
+	if errno != 0 {
+		return &os.SyscallError{Syscall: "ioprio_set", Err: errno}
+	}
+	return nil
+}
+
+// SetPersonality is a wrapper for personality(2).
+func SetPersonality(personality int) error {
+	_, _, errno := unix.Syscall(unix.SYS_PERSONALITY, uintptr(personality), 0, 0)
+	if errno != 0 {
+		return &os.SyscallError{Syscall: "personality", Err: errno}
+	}
+	return nil
+}
+
+// LandlockCreateRuleset is a wrapper for landlock_create_ruleset(2).
+func LandlockCreateRuleset(attr *unix.LandlockRulesetAttr, flags uint) (int, error) {
+	var size uintptr
+	if attr != nil {
+		size = unsafe.Sizeof(*attr)
+	}
+	fd, _, errno := unix.Syscall(unix.SYS_LANDLOCK_CREATE_RULESET, uintptr(unsafe.Pointer(attr)), size, uintptr(flags))
+	if errno != 0 {
+		return -1, &os.SyscallError{Syscall: "landlock_create_ruleset", Err: errno}
+	}
+	return int(fd), nil
+}
+
+// LandlockAddPathBeneathRule is a wrapper for landlock_add_rule(2) with
+// a LANDLOCK_RULE_PATH_BENEATH rule.
+func LandlockAddPathBeneathRule(rulesetFd int, attr *unix.LandlockPathBeneathAttr) error {
+	_, _, errno := unix.Syscall6(unix.SYS_LANDLOCK_ADD_RULE, uintptr(rulesetFd), unix.LANDLOCK_RULE_PATH_BENEATH, uintptr(unsafe.Pointer(attr)), 0, 0, 0)
+	if errno != 0 {
+		return &os.SyscallError{Syscall: "landlock_add_rule", Err: errno}
+	}
+	return nil
+}
+
+// LandlockRestrictSelf is a wrapper for landlock_restrict_self(2).
+func LandlockRestrictSelf(rulesetFd int) error {
+	_, _, errno := unix.Syscall(unix.SYS_LANDLOCK_RESTRICT_SELF, uintptr(rulesetFd), 0, 0)
+	if errno != 0 {
+		return &os.SyscallError{Syscall: "landlock_restrict_self", Err: errno}
+	}
+	return nil
+}
+
+// OpenTree is a wrapper for open_tree(2).
+func OpenTree(dirfd int, path string, flags uint) (int, error) {
+	p, err := unix.BytePtrFromString(path)
+	if err != nil {
+		return -1, err
+	}
+	fd, _, errno := unix.Syscall(unix.SYS_OPEN_TREE, uintptr(dirfd), uintptr(unsafe.Pointer(p)), uintptr(flags))
+	if errno != 0 {
+		return -1, &os.SyscallError{Syscall: "open_tree", Err: errno}
+	}
+	return int(fd), nil
+}
+
+// MoveMount is a wrapper for move_mount(2).
+func MoveMount(fromDirfd int, fromPath string, toDirfd int, toPath string, flags uint) error {
+	from, err := unix.BytePtrFromString(fromPath)
+	if err != nil {
+		return err
+	}
+	to, err := unix.BytePtrFromString(toPath)
+	if err != nil {
+		return err
+	}
+	_, _, errno := unix.Syscall6(unix.SYS_MOVE_MOUNT, uintptr(fromDirfd), uintptr(unsafe.Pointer(from)), uintptr(toDirfd), uintptr(unsafe.Pointer(to)), uintptr(flags), 0)
+	if errno != 0 {
+		return &os.SyscallError{Syscall: "move_mount", Err: errno}
+	}
+	return nil
+}
@@ -63,6 +63,10 @@ type Stat_t struct {
 	// StartTime is the number of clock ticks after system boot (since
 	// Linux 2.6).
 	StartTime uint64
+
+	// ExitCode is the exit status of the process in the form reported by
+	// waitpid(2), which is only meaningful for a zombie (since Linux 3.5).
+	ExitCode int
 }
 
 // Stat returns a Stat_t instance for the specified process.
@@ -84,6 +88,7 @@ func parseStat(data string) (stat Stat_t, err error) {
 	//    parenthesis, as it can contain spaces (and parenthesis) inside.
 	//  * field 3: process state, a single character (%c)
 	//  * field 22: process start time, a long unsigned integer (%llu).
+	//  * field 52: exit code, an integer (%d), if present.
 
 	// 1. Look for the first '(' and the last ')' first, what's in between is Name.
 	//    We expect at least 20 fields and a space after the last one.
@@ -123,5 +128,13 @@ func parseStat(data string) (stat Stat_t, err error) {
 		return stat, fmt.Errorf("invalid stat data (bad start time): %w", err)
 	}
 
+	// 4. ExitCode is field 52, which is 30 fields after StartTime.
+	if fields := strings.Fields(data[first:]); len(fields) > 52-22 {
+		stat.ExitCode, err = strconv.Atoi(fields[52-22])
+		if err != nil {
+			return stat, fmt.Errorf("invalid stat data (bad exit code): %w", err)
+		}
+	}
+
 	return stat, nil
 }
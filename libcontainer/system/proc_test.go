@@ -35,6 +35,12 @@ var procdata = map[string]Stat_t{
 		State:     'I',
 		StartTime: 0,
 	},
+	"9535 (sh) Z 9323 9535 9323 0 -1 4227084 95 0 0 0 0 0 0 0 20 0 1 0 9214970 0 0 18446744073709551615 0 0 0 0 0 0 0 0 0 0 0 0 17 1 0 0 0 0 0 0 0 0 0 0 0 0 256": {
+		Name:      "sh",
+		State:     'Z',
+		StartTime: 9214970,
+		ExitCode:  256,
+	},
 	// Not entirely correct, but minimally viable input (StartTime and a space after).
 	"1 (woo hoo) S 0 0 0 0 0 0 0 0 0 0 0 0 0 0 0 0 0 0 4 ": {
 		Name:      "woo hoo",
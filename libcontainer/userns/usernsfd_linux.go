@@ -0,0 +1,60 @@
+package userns
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/sys/unix"
+
+	"github.com/opencontainers/runc/libcontainer/configs"
+)
+
+func toSysIDMap(idMap []configs.IDMap) []syscall.SysProcIDMap {
+	sysMap := make([]syscall.SysProcIDMap, 0, len(idMap))
+	for _, m := range idMap {
+		sysMap = append(sysMap, syscall.SysProcIDMap{
+			ContainerID: m.ContainerID,
+			HostID:      m.HostID,
+			Size:        m.Size,
+		})
+	}
+	return sysMap
+}
+
+// GetUserNamespace returns a handle to a new user namespace with the given
+// uid and gid mappings. The namespace has no processes in it, so it can only
+// be used as a reference (for example, for MOUNT_ATTR_IDMAP).
+func GetUserNamespace(uidMap, gidMap []configs.IDMap) (*os.File, error) {
+	// We need some process living in a user namespace with the requested
+	// mappings. Rather than exec-ing a dummy binary, ask Go to put the child
+	// into PTRACE_TRACEME mode: the child will stop right after execve(2),
+	// long before doing anything, and we can grab its userns from procfs.
+	//
+	// Note that Go's stdlib does not support newuidmap, so this only works
+	// for mappings that we are privileged enough to write ourselves.
+	logrus.Debugf("spawning dummy process for id-mapping uid=%v gid=%v", uidMap, gidMap)
+	proc, err := os.StartProcess("/proc/self/exe", []string{"runc", "--help"}, &os.ProcAttr{
+		Sys: &syscall.SysProcAttr{
+			Cloneflags:  unix.CLONE_NEWUSER,
+			UidMappings: toSysIDMap(uidMap),
+			GidMappings: toSysIDMap(gidMap),
+			Ptrace:      true,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to spawn dummy process for id-mapping: %w", err)
+	}
+	defer func() {
+		_ = proc.Kill()
+		_, _ = proc.Wait()
+	}()
+
+	nsPath := fmt.Sprintf("/proc/%d/ns/user", proc.Pid)
+	f, err := os.Open(nsPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get userns handle: %w", err)
+	}
+	return f, nil
+}
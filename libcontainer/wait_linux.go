@@ -0,0 +1,198 @@
+package libcontainer
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/sys/unix"
+
+	"github.com/opencontainers/runc/libcontainer/cgroups"
+	"github.com/opencontainers/runc/libcontainer/cgroups/fscommon"
+	"github.com/opencontainers/runc/libcontainer/system"
+)
+
+// ExitStatus describes how the container's init process has exited.
+type ExitStatus struct {
+	// Code is the exit code of the process, or 128 plus the signal
+	// number if it was killed by a signal (like shells report it).
+	Code int
+	// Signal is the signal the process was killed by, or 0.
+	Signal unix.Signal
+}
+
+func newExitStatus(ws unix.WaitStatus) *ExitStatus {
+	if ws.Signaled() {
+		return &ExitStatus{Code: 128 + int(ws.Signal()), Signal: ws.Signal()}
+	}
+	return &ExitStatus{Code: ws.ExitStatus()}
+}
+
+func (c *linuxContainer) Wait(timeout time.Duration) (*ExitStatus, error) {
+	c.m.Lock()
+	init, startTime := c.initProcess, c.initProcessStartTime
+	// The pidfd is opened before checking the status, so that the exit
+	// status can be obtained from it if init exits in between.
+	pidfd, release := initPidfd(init, startTime)
+	defer release()
+	status, err := c.currentStatus()
+	c.m.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	if status != Stopped {
+		var deadline time.Time
+		if timeout > 0 {
+			deadline = time.Now().Add(timeout)
+		}
+		if err := c.waitInit(pidfd, deadline); err != nil {
+			return nil, err
+		}
+	}
+	return initExitStatus(init, pidfd, startTime), nil
+}
+
+// initPidfd returns a pidfd referring to init, or nil if it is not
+// available, and a function to call once done with it. For a container
+// loaded from its state, init is not a child, and the pidfd is opened now,
+// only if init still has the given start time.
+func initPidfd(init parentProcess, startTime uint64) (*os.File, func()) {
+	if init == nil {
+		return nil, func() {}
+	}
+	if pidfd := init.pidfd(); pidfd != nil {
+		return pidfd, func() {}
+	}
+	pidfd, err := openPidfdStartTime(init.pid(), startTime)
+	if err != nil || pidfd == nil {
+		return nil, func() {}
+	}
+	return pidfd, func() { _ = pidfd.Close() }
+}
+
+// waitInit blocks until the init process exits, or until the deadline
+// (if set) expires. The init pidfd is used if not nil.
+func (c *linuxContainer) waitInit(pidfd *os.File, deadline time.Time) error {
+	if pidfd != nil {
+		return waitPidfd(pidfd, deadline)
+	}
+	if cgroups.IsCgroup2UnifiedMode() {
+		if path := c.cgroupManager.Path(""); path != "" && cgroups.PathExists(path) {
+			return waitCgroupEmpty(path, deadline)
+		}
+	}
+	// Neither pidfd nor cgroup v2 is available; poll.
+	for {
+		status, err := c.Status()
+		if err != nil {
+			return err
+		}
+		if status == Stopped {
+			return nil
+		}
+		if !deadline.IsZero() && time.Now().After(deadline) {
+			return errWaitTimeout
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+var errWaitTimeout = fmt.Errorf("timed out waiting for container init to exit: %w", os.ErrDeadlineExceeded)
+
+// pollUntil waits for events on fd until the deadline (if set) expires.
+func pollUntil(fd int, deadline time.Time) error {
+	fds := []unix.PollFd{{Fd: int32(fd), Events: unix.POLLIN}}
+	for {
+		timeout := -1
+		if !deadline.IsZero() {
+			left := time.Until(deadline)
+			if left <= 0 {
+				return errWaitTimeout
+			}
+			// Round up, so we don't spin when less than 1ms is left.
+			timeout = int((left + time.Millisecond - 1) / time.Millisecond)
+		}
+		n, err := unix.Poll(fds, timeout)
+		if errors.Is(err, unix.EINTR) {
+			continue
+		}
+		if err != nil {
+			return &os.SyscallError{Syscall: "poll", Err: err}
+		}
+		if n == 1 {
+			return nil
+		}
+	}
+}
+
+// waitPidfd waits for the process referred to by pidfd to exit.
+func waitPidfd(pidfd *os.File, deadline time.Time) error {
+	return pollUntil(int(pidfd.Fd()), deadline)
+}
+
+// waitCgroupEmpty waits for the cgroup v2 at path to have no processes
+// left, using inotify on its cgroup.events file.
+func waitCgroupEmpty(path string, deadline time.Time) error {
+	fd, err := unix.InotifyInit1(unix.IN_CLOEXEC)
+	if err != nil {
+		return &os.SyscallError{Syscall: "inotify_init1", Err: err}
+	}
+	defer unix.Close(fd)
+	if _, err := unix.InotifyAddWatch(fd, filepath.Join(path, "cgroup.events"), unix.IN_MODIFY); err != nil {
+		if errors.Is(err, unix.ENOENT) {
+			// The cgroup is already removed.
+			return nil
+		}
+		return &os.SyscallError{Syscall: "inotify_add_watch", Err: err}
+	}
+	buf := make([]byte, unix.SizeofInotifyEvent+unix.NAME_MAX+1)
+	for {
+		populated, err := fscommon.GetValueByKey(path, "cgroup.events", "populated")
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if populated == 0 {
+			return nil
+		}
+		if err := pollUntil(fd, deadline); err != nil {
+			return err
+		}
+		// Drain the event(s); we only care about the file contents.
+		if _, err := unix.Read(fd, buf); err != nil && !errors.Is(err, unix.EINTR) {
+			return &os.SyscallError{Syscall: "read", Err: err}
+		}
+	}
+}
+
+// initExitStatus returns the exit status of the (exited) init process, or
+// nil if it is unknown. It can be obtained while init is a zombie, or, on
+// Linux 6.15 and later, after it is reaped by its parent, provided that its
+// pidfd (if not nil) was opened before that (that is, before init has
+// exited).
+func initExitStatus(init parentProcess, pidfd *os.File, startTime uint64) *ExitStatus {
+	if init == nil {
+		return nil
+	}
+	// Check for a zombie first, as the kernel records the exit status for
+	// the pidfd before the zombie is gone.
+	stat, err := system.Stat(init.pid())
+	if err == nil && stat.StartTime == startTime && stat.State == system.Zombie {
+		return newExitStatus(unix.WaitStatus(stat.ExitCode))
+	}
+	if pidfd != nil {
+		ws, ok, err := system.PidfdGetExitStatus(int(pidfd.Fd()))
+		if err != nil {
+			logrus.Debugf("unable to get init exit status: %v", err)
+		}
+		if ok {
+			return newExitStatus(ws)
+		}
+	}
+	return nil
+}
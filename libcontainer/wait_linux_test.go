@@ -0,0 +1,96 @@
+package libcontainer
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/opencontainers/runc/libcontainer/system"
+)
+
+func TestWaitPidfdAndExitStatus(t *testing.T) {
+	cmd := exec.Command("sleep", "100")
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer cmd.Wait() //nolint:errcheck
+	pid := cmd.Process.Pid
+	pidfd := openPidfd(pid)
+	if pidfd == nil {
+		_ = cmd.Process.Kill()
+		t.Skip("pidfd_open(2) not supported")
+	}
+	defer pidfd.Close()
+	stat, err := system.Stat(pid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	init := &mockProcess{_pid: pid, started: stat.StartTime}
+
+	err = waitPidfd(pidfd, time.Now().Add(50*time.Millisecond))
+	if !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Fatalf("expected a timeout error, got %v", err)
+	}
+	if st := initExitStatus(init, pidfd, stat.StartTime); st != nil {
+		t.Fatalf("expected no exit status for a running process, got %+v", st)
+	}
+
+	if err := cmd.Process.Kill(); err != nil {
+		t.Fatal(err)
+	}
+	if err := waitPidfd(pidfd, time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	// The process is now a zombie, as it is not reaped yet.
+	st := initExitStatus(init, pidfd, stat.StartTime)
+	if st == nil {
+		t.Fatal("expected an exit status")
+	}
+	if st.Code != 128+int(unix.SIGKILL) || st.Signal != unix.SIGKILL {
+		t.Fatalf("expected to be killed by SIGKILL, got %+v", st)
+	}
+}
+
+func TestExitStatusAfterReap(t *testing.T) {
+	cmd := exec.Command("sh", "-c", "read x; exit 3")
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	pid := cmd.Process.Pid
+	stat, err := system.Stat(pid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Like the init of a loaded container, which is not a child, and the
+	// pidfd of which is opened when waiting.
+	init := &mockProcess{_pid: pid, started: stat.StartTime}
+	pidfd, release := initPidfd(init, stat.StartTime)
+	defer release()
+	if pidfd == nil {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		t.Skip("pidfd_open(2) not supported")
+	}
+
+	_ = stdin.Close()
+	// Reap the process, like a container manager would.
+	_ = cmd.Wait()
+	if _, ok, _ := system.PidfdGetExitStatus(int(pidfd.Fd())); !ok {
+		t.Skip("PIDFD_GET_INFO with PIDFD_INFO_EXIT not supported")
+	}
+	st := initExitStatus(init, pidfd, stat.StartTime)
+	if st == nil {
+		t.Fatal("expected an exit status")
+	}
+	if st.Code != 3 || st.Signal != 0 {
+		t.Fatalf("expected exit code 3, got %+v", st)
+	}
+}
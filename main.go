@@ -119,6 +119,7 @@ func main() {
 		checkpointCommand,
 		createCommand,
 		deleteCommand,
+		debugCommand,
 		eventsCommand,
 		execCommand,
 		killCommand,
@@ -131,7 +132,9 @@ func main() {
 		specCommand,
 		startCommand,
 		stateCommand,
+		statsCommand,
 		updateCommand,
+		waitCommand,
 		featuresCommand,
 	}
 	app.Before = func(context *cli.Context) error {
@@ -0,0 +1,346 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/opencontainers/runc/libcontainer"
+	"github.com/opencontainers/runc/types"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/sys/unix"
+)
+
+// openMetricsContentType is the content type of the OpenMetrics text format.
+const openMetricsContentType = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+
+type metricLabel struct {
+	name, value string
+}
+
+type metricSample struct {
+	labels []metricLabel
+	value  string
+}
+
+type metricFamily struct {
+	name, typ, help string
+	samples         []metricSample
+}
+
+// metricSet is a set of metric families in the OpenMetrics text format.
+// The families are written in the order they were first added to, so the
+// samples of several containers are grouped by family.
+type metricSet struct {
+	families []*metricFamily
+	byName   map[string]*metricFamily
+}
+
+func newMetricSet() *metricSet {
+	return &metricSet{byName: make(map[string]*metricFamily)}
+}
+
+func (m *metricSet) add(name, typ, help, value string, labels []metricLabel) {
+	f := m.byName[name]
+	if f == nil {
+		f = &metricFamily{name: name, typ: typ, help: help}
+		m.byName[name] = f
+		m.families = append(m.families, f)
+	}
+	f.samples = append(f.samples, metricSample{labels: labels, value: value})
+}
+
+func (m *metricSet) gauge(name, help string, value uint64, labels ...metricLabel) {
+	m.add(name, "gauge", help, strconv.FormatUint(value, 10), labels)
+}
+
+func (m *metricSet) gaugeFloat(name, help string, value float64, labels ...metricLabel) {
+	m.add(name, "gauge", help, strconv.FormatFloat(value, 'g', -1, 64), labels)
+}
+
+func (m *metricSet) counter(name, help string, value uint64, labels ...metricLabel) {
+	m.add(name, "counter", help, strconv.FormatUint(value, 10), labels)
+}
+
+// counterSeconds adds a counter of seconds from a value in the given unit
+// (e.g. 1e9 for nanoseconds).
+func (m *metricSet) counterSeconds(name, help string, value uint64, unit float64, labels ...metricLabel) {
+	m.add(name, "counter", help, strconv.FormatFloat(float64(value)/unit, 'g', -1, 64), labels)
+}
+
+func (m *metricSet) info(name, help string, labels ...metricLabel) {
+	m.add(name, "info", help, "1", labels)
+}
+
+// WriteTo writes the metrics, terminated by an EOF marker.
+func (m *metricSet) WriteTo(w io.Writer) (int64, error) {
+	var b bytes.Buffer
+	for _, f := range m.families {
+		fmt.Fprintf(&b, "# TYPE %s %s\n", f.name, f.typ)
+		fmt.Fprintf(&b, "# HELP %s %s\n", f.name, f.help)
+		suffix := ""
+		switch f.typ {
+		case "counter":
+			suffix = "_total"
+		case "info":
+			suffix = "_info"
+		}
+		for _, s := range f.samples {
+			b.WriteString(f.name + suffix)
+			if len(s.labels) > 0 {
+				b.WriteByte('{')
+				for i, l := range s.labels {
+					if i > 0 {
+						b.WriteByte(',')
+					}
+					b.WriteString(l.name + `="` + escapeLabelValue(l.value) + `"`)
+				}
+				b.WriteByte('}')
+			}
+			b.WriteString(" " + s.value + "\n")
+		}
+	}
+	b.WriteString("# EOF\n")
+	return b.WriteTo(w)
+}
+
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabelValue(v string) string {
+	return labelValueEscaper.Replace(v)
+}
+
+// addStats adds the metrics of the stats s of the container id.
+func (m *metricSet) addStats(id string, s *types.Stats) {
+	l := func(labels ...string) []metricLabel {
+		res := []metricLabel{{"id", id}}
+		for i := 0; i+1 < len(labels); i += 2 {
+			res = append(res, metricLabel{labels[i], labels[i+1]})
+		}
+		return res
+	}
+
+	// CPU.
+	u := s.CPU.Usage
+	m.counterSeconds("runc_cpu_usage_seconds", "Total CPU time consumed.", u.Total, 1e9, l()...)
+	m.counterSeconds("runc_cpu_user_seconds", "CPU time consumed in user mode.", u.User, 1e9, l()...)
+	m.counterSeconds("runc_cpu_kernel_seconds", "CPU time consumed in kernel mode.", u.Kernel, 1e9, l()...)
+	for i, v := range u.Percpu {
+		m.counterSeconds("runc_cpu_percpu_usage_seconds", "CPU time consumed per CPU.", v, 1e9, l("cpu", strconv.Itoa(i))...)
+	}
+	t := s.CPU.Throttling
+	m.counter("runc_cpu_periods", "Number of CPU enforcement periods elapsed.", t.Periods, l()...)
+	m.counter("runc_cpu_throttled_periods", "Number of CPU enforcement periods throttled.", t.ThrottledPeriods, l()...)
+	m.counterSeconds("runc_cpu_throttled_seconds", "Total time the container was throttled for.", t.ThrottledTime, 1e9, l()...)
+
+	// Memory.
+	mem := s.Memory
+	m.gauge("runc_memory_cache_bytes", "Page cache memory usage.", mem.Cache, l()...)
+	for _, e := range []struct {
+		name, desc string
+		entry      types.MemoryEntry
+	}{
+		{"memory", "Memory", mem.Usage},
+		{"memory_swap", "Memory and swap", mem.Swap},
+		{"memory_kernel", "Kernel memory", mem.Kernel},
+		{"memory_kernel_tcp", "Kernel TCP buffer memory", mem.KernelTCP},
+	} {
+		m.gauge("runc_"+e.name+"_usage_bytes", e.desc+" usage.", e.entry.Usage, l()...)
+		m.gauge("runc_"+e.name+"_max_usage_bytes", e.desc+" maximum recorded usage.", e.entry.Max, l()...)
+		m.gauge("runc_"+e.name+"_limit_bytes", e.desc+" usage limit.", e.entry.Limit, l()...)
+		m.counter("runc_"+e.name+"_failcnt", e.desc+" usage limit hits.", e.entry.Failcnt, l()...)
+	}
+	keys := make([]string, 0, len(mem.Raw))
+	for k := range mem.Raw {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		m.gauge("runc_memory_stat", "Raw memory statistics of the cgroup.", mem.Raw[k], l("name", k)...)
+	}
+	ev := mem.Events
+	for _, e := range []struct {
+		name  string
+		value uint64
+	}{
+		{"low", ev.Low}, {"high", ev.High}, {"max", ev.Max},
+		{"oom", ev.OOM}, {"oom_kill", ev.OOMKill}, {"oom_group_kill", ev.OOMGroupKill},
+	} {
+		m.counter("runc_memory_events", "Number of memory events.", e.value, l("event", e.name)...)
+	}
+
+	// Pressure Stall Information.
+	for _, p := range []struct {
+		resource string
+		psi      *types.PSIStats
+	}{
+		{"cpu", s.CPU.PSI}, {"memory", s.Memory.PSI}, {"io", s.Blkio.PSI},
+	} {
+		if p.psi == nil {
+			continue
+		}
+		for _, d := range []struct {
+			kind string
+			data types.PSIData
+		}{
+			{"some", p.psi.Some}, {"full", p.psi.Full},
+		} {
+			pl := l("resource", p.resource, "kind", d.kind)
+			m.gaugeFloat("runc_pressure_avg10", "Percentage of time stalled over the last 10 seconds.", d.data.Avg10, pl...)
+			m.gaugeFloat("runc_pressure_avg60", "Percentage of time stalled over the last 60 seconds.", d.data.Avg60, pl...)
+			m.gaugeFloat("runc_pressure_avg300", "Percentage of time stalled over the last 300 seconds.", d.data.Avg300, pl...)
+			m.counterSeconds("runc_pressure_stalled_seconds", "Total time stalled.", d.data.Total, 1e6, pl...)
+		}
+	}
+
+	// Pids.
+	m.gauge("runc_pids_current", "Number of processes.", s.Pids.Current, l()...)
+	m.gauge("runc_pids_limit", "Maximum number of processes.", s.Pids.Limit, l()...)
+	m.gauge("runc_pids_peak", "Maximum recorded number of processes.", s.Pids.Peak, l()...)
+	m.counter("runc_pids_max_events", "Number of process creations denied by the limit.", s.Pids.MaxEvents, l()...)
+
+	// Block I/O.
+	b := s.Blkio
+	for _, e := range []struct {
+		name, help string
+		entries    []types.BlkioEntry
+	}{
+		{"io_service_bytes", "Number of bytes transferred to and from the device.", b.IoServiceBytesRecursive},
+		{"io_serviced", "Number of I/O operations issued to the device.", b.IoServicedRecursive},
+		{"io_queued", "Number of I/O operations queued for the device.", b.IoQueuedRecursive},
+		{"io_service_time", "Time spent by the device servicing I/O operations.", b.IoServiceTimeRecursive},
+		{"io_wait_time", "Time I/O operations spent waiting in the scheduler queues.", b.IoWaitTimeRecursive},
+		{"io_merged", "Number of I/O operations merged.", b.IoMergedRecursive},
+		{"io_time", "Time the device was allocated to the cgroup.", b.IoTimeRecursive},
+		{"sectors", "Number of sectors transferred to and from the device.", b.SectorsRecursive},
+	} {
+		for _, entry := range e.entries {
+			bl := l("major", strconv.FormatUint(entry.Major, 10), "minor", strconv.FormatUint(entry.Minor, 10), "op", entry.Op)
+			if e.name == "io_queued" {
+				m.gauge("runc_blkio_"+e.name, e.help, entry.Value, bl...)
+			} else {
+				m.counter("runc_blkio_"+e.name, e.help, entry.Value, bl...)
+			}
+		}
+	}
+
+	// Huge pages.
+	sizes := make([]string, 0, len(s.Hugetlb))
+	for k := range s.Hugetlb {
+		sizes = append(sizes, k)
+	}
+	sort.Strings(sizes)
+	for _, size := range sizes {
+		h := s.Hugetlb[size]
+		m.gauge("runc_hugetlb_usage_bytes", "Huge pages usage.", h.Usage, l("pagesize", size)...)
+		m.gauge("runc_hugetlb_max_usage_bytes", "Huge pages maximum recorded usage.", h.Max, l("pagesize", size)...)
+		m.counter("runc_hugetlb_failcnt", "Huge pages usage limit hits.", h.Failcnt, l("pagesize", size)...)
+	}
+
+	// Intel RDT.
+	rdt := s.IntelRdt
+	if rdt.L3CacheSchema != "" || rdt.MemBwSchema != "" {
+		m.info("runc_intel_rdt_schema", "Intel RDT schemata of the container.",
+			l("l3_cache_schema", rdt.L3CacheSchema, "mem_bw_schema", rdt.MemBwSchema)...)
+	}
+	if rdt.MBMStats != nil {
+		for i, st := range *rdt.MBMStats {
+			nl := l("numa_node", strconv.Itoa(i))
+			m.counter("runc_intel_rdt_mbm_total_bytes", "Total memory bandwidth used.", st.MBMTotalBytes, nl...)
+			m.counter("runc_intel_rdt_mbm_local_bytes", "Local memory bandwidth used.", st.MBMLocalBytes, nl...)
+		}
+	}
+	if rdt.CMTStats != nil {
+		for i, st := range *rdt.CMTStats {
+			m.gauge("runc_intel_rdt_llc_occupancy_bytes", "Last level cache occupancy.", st.LLCOccupancy, l("numa_node", strconv.Itoa(i))...)
+		}
+	}
+
+	// Network.
+	for _, iface := range s.NetworkInterfaces {
+		il := l("interface", iface.Name)
+		m.counter("runc_network_receive_bytes", "Number of bytes received.", iface.RxBytes, il...)
+		m.counter("runc_network_receive_packets", "Number of packets received.", iface.RxPackets, il...)
+		m.counter("runc_network_receive_errors", "Number of receive errors.", iface.RxErrors, il...)
+		m.counter("runc_network_receive_dropped", "Number of received packets dropped.", iface.RxDropped, il...)
+		m.counter("runc_network_receive_qdisc_dropped", "Number of received packets dropped by the ingress bandwidth limit.", iface.RxQdiscDropped, il...)
+		m.counter("runc_network_receive_multicast", "Number of multicast packets received.", iface.Multicast, il...)
+		m.counter("runc_network_transmit_bytes", "Number of bytes sent.", iface.TxBytes, il...)
+		m.counter("runc_network_transmit_packets", "Number of packets sent.", iface.TxPackets, il...)
+		m.counter("runc_network_transmit_errors", "Number of transmit errors.", iface.TxErrors, il...)
+		m.counter("runc_network_transmit_dropped", "Number of sent packets dropped.", iface.TxDropped, il...)
+		m.counter("runc_network_transmit_qdisc_dropped", "Number of sent packets dropped by the egress bandwidth limit.", iface.TxQdiscDropped, il...)
+		m.counter("runc_network_transmit_collisions", "Number of collisions.", iface.Collisions, il...)
+	}
+}
+
+// listenMetrics listens on addr, which is either "unix:<path>" or
+// "tcp:<address>".
+func listenMetrics(addr string) (net.Listener, error) {
+	for _, network := range []string{"unix", "tcp"} {
+		if strings.HasPrefix(addr, network+":") {
+			return net.Listen(network, strings.TrimPrefix(addr, network+":"))
+		}
+	}
+	return nil, fmt.Errorf("invalid listen address %q: must be unix:<path> or tcp:<address>", addr)
+}
+
+// serveMetrics serves the stats of container selected by mask in the
+// OpenMetrics format on /metrics at addr, until the container stops or a
+// termination signal is received. The container status is checked every
+// interval.
+func serveMetrics(container libcontainer.Container, mask libcontainer.StatsMask, addr string, interval time.Duration) error {
+	l, err := listenMetrics(addr)
+	if err != nil {
+		return err
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		var s libcontainer.Stats
+		if err := container.FillStats(&s, mask); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		m := newMetricSet()
+		if stats := convertLibcontainerStats(&s); stats != nil {
+			m.addStats(container.ID(), stats)
+		}
+		w.Header().Set("Content-Type", openMetricsContentType)
+		if _, err := m.WriteTo(w); err != nil {
+			logrus.Debugf("unable to write metrics: %v", err)
+		}
+	})
+	srv := &http.Server{Handler: mux}
+	served := make(chan error, 1)
+	go func() {
+		served <- srv.Serve(l)
+	}()
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, unix.SIGINT, unix.SIGTERM)
+	defer signal.Stop(sigs)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case err := <-served:
+			return err
+		case <-sigs:
+			return srv.Close()
+		case <-ticker.C:
+			status, err := container.Status()
+			if err != nil || status == libcontainer.Stopped {
+				return srv.Close()
+			}
+		}
+	}
+}
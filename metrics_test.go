@@ -0,0 +1,187 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/opencontainers/runc/types"
+)
+
+// metricFamilyOf returns the family name of a line of the OpenMetrics text
+// format, or "" for the EOF marker.
+func metricFamilyOf(line string) string {
+	if strings.HasPrefix(line, "# TYPE ") || strings.HasPrefix(line, "# HELP ") {
+		return strings.Fields(line)[2]
+	}
+	if line == "# EOF" {
+		return ""
+	}
+	name := line[:strings.IndexAny(line, "{ ")]
+	return strings.TrimSuffix(strings.TrimSuffix(name, "_total"), "_info")
+}
+
+func TestMetricsStats(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		id    string
+		stats types.Stats
+		// families are the families to compare, in addition to the EOF
+		// marker; all the others are ignored.
+		families []string
+		want     string
+	}{
+		{
+			name: "cpu",
+			id:   "test",
+			stats: types.Stats{CPU: types.Cpu{Usage: types.CpuUsage{
+				Total:  1500000000,
+				Percpu: []uint64{1000000000, 500000000},
+			}}},
+			families: []string{"runc_cpu_usage_seconds", "runc_cpu_percpu_usage_seconds"},
+			want: `# TYPE runc_cpu_usage_seconds counter
+# HELP runc_cpu_usage_seconds Total CPU time consumed.
+runc_cpu_usage_seconds_total{id="test"} 1.5
+# TYPE runc_cpu_percpu_usage_seconds counter
+# HELP runc_cpu_percpu_usage_seconds CPU time consumed per CPU.
+runc_cpu_percpu_usage_seconds_total{id="test",cpu="0"} 1
+runc_cpu_percpu_usage_seconds_total{id="test",cpu="1"} 0.5
+# EOF
+`,
+		},
+		{
+			name: "memory raw stats are sorted",
+			id:   "test",
+			stats: types.Stats{Memory: types.Memory{
+				Usage: types.MemoryEntry{Usage: 4096, Limit: 8192},
+				Raw:   map[string]uint64{"rss": 2, "cache": 1},
+			}},
+			families: []string{"runc_memory_usage_bytes", "runc_memory_limit_bytes", "runc_memory_stat"},
+			want: `# TYPE runc_memory_usage_bytes gauge
+# HELP runc_memory_usage_bytes Memory usage.
+runc_memory_usage_bytes{id="test"} 4096
+# TYPE runc_memory_limit_bytes gauge
+# HELP runc_memory_limit_bytes Memory usage limit.
+runc_memory_limit_bytes{id="test"} 8192
+# TYPE runc_memory_stat gauge
+# HELP runc_memory_stat Raw memory statistics of the cgroup.
+runc_memory_stat{id="test",name="cache"} 1
+runc_memory_stat{id="test",name="rss"} 2
+# EOF
+`,
+		},
+		{
+			name: "pressure is only reported when available",
+			id:   "test",
+			stats: types.Stats{Memory: types.Memory{PSI: &types.PSIStats{
+				Some: types.PSIData{Avg10: 1.25, Total: 2500000},
+			}}},
+			families: []string{"runc_pressure_avg10", "runc_pressure_stalled_seconds"},
+			want: `# TYPE runc_pressure_avg10 gauge
+# HELP runc_pressure_avg10 Percentage of time stalled over the last 10 seconds.
+runc_pressure_avg10{id="test",resource="memory",kind="some"} 1.25
+runc_pressure_avg10{id="test",resource="memory",kind="full"} 0
+# TYPE runc_pressure_stalled_seconds counter
+# HELP runc_pressure_stalled_seconds Total time stalled.
+runc_pressure_stalled_seconds_total{id="test",resource="memory",kind="some"} 2.5
+runc_pressure_stalled_seconds_total{id="test",resource="memory",kind="full"} 0
+# EOF
+`,
+		},
+		{
+			name: "intel rdt info",
+			id:   "test",
+			stats: types.Stats{IntelRdt: types.IntelRdt{
+				L3CacheSchema: "L3:0=ff",
+			}},
+			families: []string{"runc_intel_rdt_schema"},
+			want: `# TYPE runc_intel_rdt_schema info
+# HELP runc_intel_rdt_schema Intel RDT schemata of the container.
+runc_intel_rdt_schema_info{id="test",l3_cache_schema="L3:0=ff",mem_bw_schema=""} 1
+# EOF
+`,
+		},
+		{
+			name: "network",
+			id:   "test",
+			stats: types.Stats{NetworkInterfaces: []*types.NetworkInterface{
+				{Name: "eth0", RxBytes: 100, TxQdiscDropped: 3},
+			}},
+			families: []string{"runc_network_receive_bytes", "runc_network_transmit_qdisc_dropped"},
+			want: `# TYPE runc_network_receive_bytes counter
+# HELP runc_network_receive_bytes Number of bytes received.
+runc_network_receive_bytes_total{id="test",interface="eth0"} 100
+# TYPE runc_network_transmit_qdisc_dropped counter
+# HELP runc_network_transmit_qdisc_dropped Number of sent packets dropped by the egress bandwidth limit.
+runc_network_transmit_qdisc_dropped_total{id="test",interface="eth0"} 3
+# EOF
+`,
+		},
+		{
+			name: "label escaping",
+			id:   "a\"b\\c\nd",
+			stats: types.Stats{Pids: types.Pids{Current: 1}, Memory: types.Memory{
+				Raw: map[string]uint64{`x"y`: 1},
+			}},
+			families: []string{"runc_pids_current", "runc_memory_stat"},
+			want: `# TYPE runc_memory_stat gauge
+# HELP runc_memory_stat Raw memory statistics of the cgroup.
+runc_memory_stat{id="a\"b\\c\nd",name="x\"y"} 1
+# TYPE runc_pids_current gauge
+# HELP runc_pids_current Number of processes.
+runc_pids_current{id="a\"b\\c\nd"} 1
+# EOF
+`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			m := newMetricSet()
+			m.addStats(tc.id, &tc.stats)
+			var b bytes.Buffer
+			if _, err := m.WriteTo(&b); err != nil {
+				t.Fatal(err)
+			}
+			out := b.String()
+			if !strings.HasSuffix(out, "\n# EOF\n") || strings.Count(out, "# EOF") != 1 {
+				t.Fatalf("expected the output to end with a single EOF marker, got:\n%s", out)
+			}
+
+			keep := map[string]bool{"": true}
+			for _, f := range tc.families {
+				keep[f] = true
+			}
+			var got strings.Builder
+			for _, line := range strings.SplitAfter(out, "\n") {
+				if line != "" && keep[metricFamilyOf(strings.TrimSuffix(line, "\n"))] {
+					got.WriteString(line)
+				}
+			}
+			if got.String() != tc.want {
+				t.Errorf("expected:\n%s\ngot:\n%s", tc.want, got.String())
+			}
+		})
+	}
+}
+
+func TestMetricsMultipleContainers(t *testing.T) {
+	m := newMetricSet()
+	m.addStats("a", &types.Stats{Pids: types.Pids{Current: 1}})
+	m.addStats("b", &types.Stats{Pids: types.Pids{Current: 2}})
+	var b bytes.Buffer
+	if _, err := m.WriteTo(&b); err != nil {
+		t.Fatal(err)
+	}
+
+	// The samples of both containers are grouped in a single family.
+	want := `# TYPE runc_pids_current gauge
+# HELP runc_pids_current Number of processes.
+runc_pids_current{id="a"} 1
+runc_pids_current{id="b"} 2
+`
+	if !strings.Contains(b.String(), want) {
+		t.Errorf("expected the output to contain:\n%s\ngot:\n%s", want, b.String())
+	}
+	if strings.Count(b.String(), "# TYPE runc_pids_current ") != 1 {
+		t.Error("expected a single runc_pids_current family")
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/opencontainers/runc/libcontainer/configs"
+	"github.com/opencontainers/runc/libcontainer/seccomp"
+	"github.com/sirupsen/logrus"
+	"github.com/urfave/cli"
+)
+
+// seccompRecorder records the system calls made by a container, to write a
+// seccomp profile allowing them once it exits.
+type seccompRecorder struct {
+	recorder    *seccomp.Recorder
+	socketPath  string
+	profilePath string
+}
+
+func newSeccompRecorder(context *cli.Context, id string) (*seccompRecorder, error) {
+	profilePath := context.String("seccomp-record")
+	if profilePath == "" {
+		return nil, nil
+	}
+	if context.Bool("detach") {
+		return nil, errors.New("--seccomp-record can't be used with --detach")
+	}
+	if !seccomp.Enabled {
+		return nil, errors.New("--seccomp-record requires runc to be built with seccomp support")
+	}
+	// The listener path is used by runc init, which may run in another
+	// working directory.
+	root, err := filepath.Abs(context.GlobalString("root"))
+	if err != nil {
+		return nil, err
+	}
+	return &seccompRecorder{
+		socketPath:  filepath.Join(root, id, "seccomp-record.sock"),
+		profilePath: profilePath,
+	}, nil
+}
+
+// setupConfig replaces the seccomp profile of the container with one
+// notifying all its system calls to the recorder. The architectures of the
+// original profile, if any, are kept.
+func (s *seccompRecorder) setupConfig(config *configs.Config) {
+	var archs []string
+	if config.Seccomp != nil {
+		logrus.Warn("the seccomp profile of the container is not enforced while recording")
+		archs = config.Seccomp.Architectures
+	}
+	config.Seccomp = seccomp.RecordingProfile(s.socketPath, archs)
+}
+
+// start starts listening for the seccomp fd of the container. The container
+// state directory must exist.
+func (s *seccompRecorder) start() (err error) {
+	s.recorder, err = seccomp.NewRecorder(s.socketPath)
+	return err
+}
+
+// Close stops recording, if started.
+func (s *seccompRecorder) Close() error {
+	if s.recorder == nil {
+		return nil
+	}
+	return s.recorder.Close()
+}
+
+// writeProfile stops recording and writes the recorded profile of the native
+// architecture as the linux.seccomp section of a runtime spec. The profiles of
+// the other architectures system calls were made on, if any, are written next
+// to it, with the architecture name as a suffix (e.g. profile.json.x86).
+func (s *seccompRecorder) writeProfile() error {
+	if err := s.Close(); err != nil {
+		return err
+	}
+	for i, profile := range s.recorder.Profiles() {
+		path := s.profilePath
+		if i > 0 {
+			arch := strings.TrimPrefix(string(profile.Architectures[0]), "SCMP_ARCH_")
+			path += "." + strings.ToLower(arch)
+		}
+		data, err := json.MarshalIndent(profile, "", "\t")
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
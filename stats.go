@@ -0,0 +1,340 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/docker/go-units"
+	"github.com/opencontainers/runc/libcontainer"
+	"github.com/opencontainers/runc/types"
+	"github.com/urfave/cli"
+	"golang.org/x/sys/unix"
+)
+
+// containerStats is the resource usage of a container over a sampling
+// interval, as displayed by runc stats.
+type containerStats struct {
+	// ID is the container ID.
+	ID string `json:"id"`
+	// Status is the container status at the end of the interval.
+	Status string `json:"status"`
+	// CPUPercent is the CPU usage, in percent of a single CPU.
+	CPUPercent float64 `json:"cpuPercent"`
+	// MemoryUsage is the memory usage, in bytes, not counting the inactive
+	// page cache.
+	MemoryUsage uint64 `json:"memoryUsage"`
+	// MemoryLimit is the memory limit, in bytes, or the host memory if the
+	// container is not limited.
+	MemoryLimit uint64 `json:"memoryLimit"`
+	// MemoryPercent is the memory usage, in percent of the limit.
+	MemoryPercent float64 `json:"memoryPercent"`
+	// Pids is the number of processes.
+	Pids uint64 `json:"pids"`
+	// BlockRead and BlockWrite are the block I/O rates, in bytes per second.
+	BlockRead  float64 `json:"blockReadRate"`
+	BlockWrite float64 `json:"blockWriteRate"`
+	// NetRx and NetTx are the network I/O rates of all the interfaces, in
+	// bytes per second.
+	NetRx float64 `json:"netRxRate"`
+	NetTx float64 `json:"netTxRate"`
+}
+
+// statsSample is a sample of the stats of a container.
+type statsSample struct {
+	status string
+	stats  *types.Stats
+	time   time.Time
+}
+
+var statsCommand = cli.Command{
+	Name:  "stats",
+	Usage: "display the resource usage rates of containers",
+	ArgsUsage: `[container-id...]
+
+Where "<container-id>" is the name for the instance of the container. If no
+container is given, the stats of all the running containers with the given
+root are displayed.`,
+	Description: `The stats command samples the stats of the containers every interval, and
+displays their CPU and memory usage, number of processes, and block and network
+I/O rates. By default, the stats are refreshed until interrupted.`,
+	Flags: []cli.Flag{
+		cli.BoolFlag{Name: "all, a", Usage: "display all the containers, not only the running ones"},
+		cli.BoolFlag{Name: "no-stream", Usage: "display the stats once then exit"},
+		cli.StringFlag{Name: "format, f", Value: "table", Usage: `select one of: ` + formatOptions},
+		cli.DurationFlag{Name: "interval", Value: time.Second, Usage: "set the stats sampling interval"},
+	},
+	Action: func(context *cli.Context) error {
+		format := context.String("format")
+		if format != "table" && format != "json" {
+			return errors.New("invalid format option")
+		}
+		interval := context.Duration("interval")
+		if interval <= 0 {
+			return errors.New("duration interval must be greater than 0")
+		}
+		factory, err := loadFactory(context)
+		if err != nil {
+			return err
+		}
+		ids := context.Args()
+		if len(ids) > 0 && context.Bool("all") {
+			return errors.New("--all can't be used with container IDs")
+		}
+		// The containers are loaded once, and reused for all the samples.
+		containers := make(map[string]libcontainer.Container)
+		load := func(id string) (libcontainer.Container, libcontainer.Status, error) {
+			if c, ok := containers[id]; ok {
+				// A stopped container may have been deleted and another
+				// one created with the same id, so it is loaded again.
+				if status, err := c.Status(); err == nil && status != libcontainer.Stopped {
+					return c, status, nil
+				}
+			}
+			delete(containers, id)
+			c, err := factory.Load(id)
+			if err != nil {
+				return nil, 0, err
+			}
+			status, err := c.Status()
+			if err != nil {
+				return nil, 0, fmt.Errorf("status for %s: %w", id, err)
+			}
+			containers[id] = c
+			return c, status, nil
+		}
+		// Fail early on unknown containers.
+		for _, id := range ids {
+			if _, _, err := load(id); err != nil {
+				return err
+			}
+		}
+		list := func() ([]string, error) {
+			if len(ids) > 0 {
+				return ids, nil
+			}
+			entries, err := os.ReadDir(context.GlobalString("root"))
+			if err != nil {
+				return nil, err
+			}
+			var res []string
+			for _, e := range entries {
+				if e.IsDir() {
+					res = append(res, e.Name())
+				}
+			}
+			return res, nil
+		}
+
+		prev := make(map[string]*statsSample)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for first := true; ; first = false {
+			current, err := list()
+			if err != nil {
+				return err
+			}
+			listed := make(map[string]bool)
+			samples := make(map[string]*statsSample)
+			for _, id := range current {
+				listed[id] = true
+				container, status, err := load(id)
+				if err != nil {
+					// The container may have been deleted since.
+					if !errors.Is(err, libcontainer.ErrNotExist) {
+						fmt.Fprintf(os.Stderr, "load container %s: %v\n", id, err)
+					}
+					continue
+				}
+				if len(ids) == 0 && !context.Bool("all") && status != libcontainer.Running {
+					continue
+				}
+				if s := sampleStats(container, status); s != nil {
+					samples[id] = s
+				}
+			}
+			for id := range containers {
+				if !listed[id] {
+					delete(containers, id)
+				}
+			}
+			// Rates need two samples, so wait for the second one the
+			// first time, then display the stats at every interval.
+			if !first {
+				var res []*containerStats
+				for _, id := range current {
+					if s := samples[id]; s != nil {
+						res = append(res, computeStats(id, prev[id], s))
+					}
+				}
+				if err := printStats(res, format, !context.Bool("no-stream")); err != nil {
+					return err
+				}
+				if context.Bool("no-stream") {
+					return nil
+				}
+			}
+			prev = samples
+			<-ticker.C
+		}
+	},
+}
+
+// statsMask selects the stats runc stats needs.
+const statsMask = libcontainer.StatsCPU | libcontainer.StatsMemory | libcontainer.StatsPids |
+	libcontainer.StatsBlkio | libcontainer.StatsNetwork
+
+// sampleStats returns the current stats of the container, which has the
+// given status, or nil if they can't be obtained. Only running and paused
+// containers have stats.
+func sampleStats(container libcontainer.Container, status libcontainer.Status) *statsSample {
+	s := &statsSample{status: status.String(), time: time.Now()}
+	if status == libcontainer.Running || status == libcontainer.Paused {
+		var ls libcontainer.Stats
+		if err := container.FillStats(&ls, statsMask); err != nil {
+			fmt.Fprintf(os.Stderr, "stats for %s: %v\n", container.ID(), err)
+			return nil
+		}
+		s.stats = convertLibcontainerStats(&ls)
+	}
+	return s
+}
+
+// computeStats computes the stats of the container id over the interval
+// between the samples prev, which may be nil, and cur.
+func computeStats(id string, prev, cur *statsSample) *containerStats {
+	res := &containerStats{ID: id, Status: cur.status}
+	s := cur.stats
+	if s == nil {
+		return res
+	}
+	res.MemoryUsage = memoryUsage(s)
+	res.MemoryLimit = s.Memory.Usage.Limit
+	if total := hostMemory(); total != 0 && (res.MemoryLimit == 0 || res.MemoryLimit > total) {
+		res.MemoryLimit = total
+	}
+	if res.MemoryLimit != 0 {
+		res.MemoryPercent = float64(res.MemoryUsage) / float64(res.MemoryLimit) * 100
+	}
+	res.Pids = s.Pids.Current
+
+	if prev == nil || prev.stats == nil {
+		return res
+	}
+	p := prev.stats
+	elapsed := cur.time.Sub(prev.time).Seconds()
+	if elapsed <= 0 {
+		return res
+	}
+	rate := func(cur, prev uint64) float64 {
+		if cur < prev {
+			// The counter was reset.
+			return 0
+		}
+		return float64(cur-prev) / elapsed
+	}
+	// CPU usage is in nanoseconds.
+	res.CPUPercent = rate(s.CPU.Usage.Total, p.CPU.Usage.Total) / 1e7
+	read, write := blockIO(s)
+	prevRead, prevWrite := blockIO(p)
+	res.BlockRead = rate(read, prevRead)
+	res.BlockWrite = rate(write, prevWrite)
+	rx, tx := netIO(s)
+	prevRx, prevTx := netIO(p)
+	res.NetRx = rate(rx, prevRx)
+	res.NetTx = rate(tx, prevTx)
+	return res
+}
+
+// memoryUsage returns the memory usage of s without the inactive page
+// cache, which can be reclaimed.
+func memoryUsage(s *types.Stats) uint64 {
+	usage := s.Memory.Usage.Usage
+	// cgroup v1 reports the hierarchical stats with a total_ prefix.
+	inactive, ok := s.Memory.Raw["total_inactive_file"]
+	if !ok {
+		inactive = s.Memory.Raw["inactive_file"]
+	}
+	if inactive < usage {
+		usage -= inactive
+	}
+	return usage
+}
+
+// hostMemory returns the total memory of the host, in bytes, or 0 if it
+// can't be determined.
+func hostMemory() uint64 {
+	var info unix.Sysinfo_t
+	if err := unix.Sysinfo(&info); err != nil {
+		return 0
+	}
+	return uint64(info.Totalram) * uint64(info.Unit)
+}
+
+// blockIO returns the numbers of bytes read and written by s.
+func blockIO(s *types.Stats) (read, write uint64) {
+	for _, e := range s.Blkio.IoServiceBytesRecursive {
+		switch strings.ToLower(e.Op) {
+		case "read":
+			read += e.Value
+		case "write":
+			write += e.Value
+		}
+	}
+	return read, write
+}
+
+// netIO returns the numbers of bytes received and sent by s.
+func netIO(s *types.Stats) (rx, tx uint64) {
+	for _, iface := range s.NetworkInterfaces {
+		rx += iface.RxBytes
+		tx += iface.TxBytes
+	}
+	return rx, tx
+}
+
+func isTerminal(f *os.File) bool {
+	_, err := unix.IoctlGetTermios(int(f.Fd()), unix.TCGETS)
+	return err == nil
+}
+
+// printStats displays stats in the given format. In table format, if
+// refresh is true and the output is a terminal, the screen is cleared
+// first.
+func printStats(stats []*containerStats, format string, refresh bool) error {
+	if format == "json" {
+		if stats == nil {
+			stats = []*containerStats{}
+		}
+		return json.NewEncoder(os.Stdout).Encode(stats)
+	}
+	if refresh && isTerminal(os.Stdout) {
+		// Move the cursor home and clear the screen.
+		fmt.Print("\033[H\033[2J")
+	}
+	w := tabwriter.NewWriter(os.Stdout, 12, 1, 3, ' ', 0)
+	fmt.Fprint(w, "ID\tSTATUS\tCPU %\tMEM USAGE / LIMIT\tMEM %\tPIDS\tBLOCK I/O (R / W)\tNET I/O (RX / TX)\n")
+	for _, s := range stats {
+		fmt.Fprintf(w, "%s\t%s\t%.2f%%\t%s / %s\t%.2f%%\t%d\t%s / %s\t%s / %s\n",
+			s.ID,
+			s.Status,
+			s.CPUPercent,
+			units.BytesSize(float64(s.MemoryUsage)),
+			units.BytesSize(float64(s.MemoryLimit)),
+			s.MemoryPercent,
+			s.Pids,
+			humanRate(s.BlockRead),
+			humanRate(s.BlockWrite),
+			humanRate(s.NetRx),
+			humanRate(s.NetTx))
+	}
+	return w.Flush()
+}
+
+func humanRate(r float64) string {
+	return units.HumanSize(r) + "/s"
+}
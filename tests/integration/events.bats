@@ -100,3 +100,61 @@ function test_events() {
 
 	grep -q '{"type":"oom","id":"test_busybox"}' events.log
 }
+
+@test "events --stats --format openmetrics" {
+	# XXX: currently cgroups require root containers.
+	requires root
+	init_cgroup_paths
+
+	runc run -d --console-socket "$CONSOLE_SOCKET" test_busybox
+	[ "$status" -eq 0 ]
+
+	runc events --stats --format openmetrics test_busybox
+	[ "$status" -eq 0 ]
+	[[ "$output" == *'runc_cpu_usage_seconds_total{id="test_busybox"} '* ]]
+	[[ "$output" == *'runc_pids_current{id="test_busybox"} '* ]]
+	[ "${lines[-1]}" = "# EOF" ]
+}
+
+@test "events --listen" {
+	# XXX: currently cgroups require root containers.
+	requires root
+	init_cgroup_paths
+	command -v curl &>/dev/null || skip "test requires curl"
+
+	runc run -d --console-socket "$CONSOLE_SOCKET" test_busybox
+	[ "$status" -eq 0 ]
+
+	runc events --listen tcp:127.0.0.1:0 --format json test_busybox
+	[ "$status" -ne 0 ]
+	[[ "$output" == *"only supports the openmetrics format"* ]]
+
+	(__runc events --listen "unix:$ROOT/metrics.sock" test_busybox) &
+	retry 10 1 [ -S "$ROOT/metrics.sock" ]
+	run curl -sf --unix-socket "$ROOT/metrics.sock" http://localhost/metrics
+	[ "$status" -eq 0 ]
+	[[ "$output" == *'runc_cpu_usage_seconds_total{id="test_busybox"} '* ]]
+
+	# The server exits once the container is gone.
+	runc delete -f test_busybox
+	[ "$status" -eq 0 ]
+	wait
+}
+
+@test "events --stats --stats-only" {
+	# XXX: currently cgroups require root containers.
+	requires root
+	init_cgroup_paths
+
+	runc run -d --console-socket "$CONSOLE_SOCKET" test_busybox
+	[ "$status" -eq 0 ]
+
+	runc events --stats --stats-only=memory,cpu test_busybox
+	[ "$status" -eq 0 ]
+	[ "$(echo "$output" | jq '.data.memory.usage.usage')" -gt 0 ]
+	[ "$(echo "$output" | jq '.data.pids.current')" = "null" ]
+
+	runc events --stats --stats-only=foo test_busybox
+	[ "$status" -ne 0 ]
+	[[ "$output" == *'invalid stats "foo"'* ]]
+}
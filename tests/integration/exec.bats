@@ -298,3 +298,92 @@ function check_exec_debug() {
 	runc exec --cgroup second test_busybox grep -w second /proc/self/cgroup
 	[ "$status" -eq 0 ]
 }
+
+@test "runc exec --sched-policy --nice" {
+	runc run -d --console-socket "$CONSOLE_SOCKET" test_busybox
+	[ "$status" -eq 0 ]
+
+	# Fields 19 and 41 of /proc/PID/stat are nice and policy.
+	runc exec --sched-policy batch --nice 10 test_busybox awk '{print $19, $41}' /proc/self/stat
+	[ "$status" -eq 0 ]
+	[[ "$output" == "10 3" ]]
+
+	runc exec --nice 5 test_busybox awk '{print $19, $41}' /proc/self/stat
+	[ "$status" -eq 0 ]
+	[[ "$output" == "5 0" ]]
+
+	runc exec --sched-policy foo test_busybox true
+	[ "$status" -ne 0 ]
+	[[ "$output" == *"invalid scheduler policy"* ]]
+}
+
+@test "runc exec --sched-priority" {
+	# On cgroup v1, a realtime policy requires a realtime runtime for the
+	# container cgroup and all its parents (see "update rt period and runtime").
+	requires root cgroups_v2
+
+	runc run -d --console-socket "$CONSOLE_SOCKET" test_busybox
+	[ "$status" -eq 0 ]
+
+	# Fields 18, 40 and 41 of /proc/PID/stat are priority, rt_priority and policy.
+	runc exec --sched-policy fifo --sched-priority 10 test_busybox awk '{print $18, $40, $41}' /proc/self/stat
+	[ "$status" -eq 0 ]
+	[[ "$output" == "-11 10 1" ]]
+}
+
+@test "runc exec --sched-priority [invalid]" {
+	runc run -d --console-socket "$CONSOLE_SOCKET" test_busybox
+	[ "$status" -eq 0 ]
+
+	runc exec --sched-policy fifo test_busybox true
+	[ "$status" -ne 0 ]
+	[[ "$output" == *"invalid scheduler priority"* ]]
+
+	runc exec --sched-policy batch --sched-priority 10 test_busybox true
+	[ "$status" -ne 0 ]
+	[[ "$output" == *"scheduler priority can only be set"* ]]
+
+	runc exec --sched-priority 10 test_busybox true
+	[ "$status" -ne 0 ]
+	[[ "$output" == *"requires a SCHED_FIFO or SCHED_RR"* ]]
+}
+
+@test "runc exec --io-priority" {
+	runc run -d --console-socket "$CONSOLE_SOCKET" test_busybox
+	[ "$status" -eq 0 ]
+
+	# busybox ionice prints the I/O class and priority of the process.
+	runc exec --io-priority be:6 test_busybox ionice
+	[ "$status" -eq 0 ]
+	[[ "$output" == *"best-effort: prio 6"* ]]
+
+	runc exec --io-priority be:8 test_busybox true
+	[ "$status" -ne 0 ]
+	[[ "$output" == *"invalid io priority"* ]]
+}
+
+@test "runc exec --cpu-affinity" {
+	runc run -d --console-socket "$CONSOLE_SOCKET" test_busybox
+	[ "$status" -eq 0 ]
+
+	runc exec --cpu-affinity 0 test_busybox grep -w Cpus_allowed_list /proc/self/status
+	[ "$status" -eq 0 ]
+	[[ "$output" == *"Cpus_allowed_list:"*"0" ]]
+
+	# The initial affinity is set by nsexec, as it is reset by the kernel
+	# if the process is started directly in the container cgroup.
+	runc --debug exec --cpu-affinity 0 test_busybox true
+	[ "$status" -eq 0 ]
+	[[ "$output" == *"nsexec"*"set initial cpu affinity to 0"* ]]
+
+	if [ "$(nproc)" -gt 1 ]; then
+		runc --debug exec --cpu-affinity 1:0 test_busybox grep -w Cpus_allowed_list /proc/self/status
+		[ "$status" -eq 0 ]
+		[[ "$output" == *"nsexec"*"set initial cpu affinity to 1"* ]]
+		[[ "$output" == *"Cpus_allowed_list:"*"0" ]]
+	fi
+
+	runc exec --cpu-affinity 1-0 test_busybox true
+	[ "$status" -ne 0 ]
+	[[ "$output" == *"invalid CPU list"* ]]
+}
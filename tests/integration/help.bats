@@ -78,6 +78,10 @@ load helpers
 	[ "$status" -eq 0 ]
 	[[ ${lines[1]} =~ runc\ update+ ]]
 
+	runc wait -h
+	[ "$status" -eq 0 ]
+	[[ ${lines[1]} =~ runc\ wait+ ]]
+
 }
 
 @test "runc foo -h" {
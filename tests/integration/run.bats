@@ -57,3 +57,41 @@ function teardown() {
 	runc state test_run_keep
 	[ "$status" -ne 0 ]
 }
+
+@test "runc run [personality LINUX32]" {
+	requires arch_x86_64
+
+	update_config '	  .linux.personality = {"domain": "LINUX32"}
+			| .process.args = ["uname", "-m"]'
+
+	runc run test_personality
+	[ "$status" -eq 0 ]
+	[[ "$output" == "i686" ]]
+}
+
+@test "runc exec [personality LINUX32]" {
+	requires arch_x86_64
+
+	update_config '.linux.personality = {"domain": "LINUX32"}'
+
+	runc run -d --console-socket "$CONSOLE_SOCKET" test_personality
+	[ "$status" -eq 0 ]
+
+	runc exec test_personality uname -m
+	[ "$status" -eq 0 ]
+	[[ "$output" == "i686" ]]
+}
+
+@test "runc run [scheduler utilization clamp]" {
+	# The uclamp values are only shown with CONFIG_UCLAMP_TASK.
+	grep -q uclamp.min /proc/self/sched || skip "uclamp not supported"
+
+	update_config '	  .process.scheduler = {"policy": "SCHED_OTHER", "flags": ["SCHED_FLAG_UTIL_CLAMP_MIN", "SCHED_FLAG_UTIL_CLAMP_MAX"]}
+			| .annotations += {"org.opencontainers.runc.sched.util_min": "256", "org.opencontainers.runc.sched.util_max": "512"}
+			| .process.args = ["grep", "^uclamp.m", "/proc/self/sched"]'
+
+	runc run test_uclamp
+	[ "$status" -eq 0 ]
+	[[ "${lines[0]}" == "uclamp.min"*"256" ]]
+	[[ "${lines[1]}" == "uclamp.max"*"512" ]]
+}
@@ -112,6 +112,16 @@ function scmp_act_notify_template() {
 	[ "$status" -ne 0 ]
 }
 
+@test "runc run [seccomp] (SCMP_ACT_NOTIFY default action)" {
+	scmp_act_notify_template "/bin/true" false '"mkdir"'
+	update_config '   .linux.seccomp.defaultAction = "SCMP_ACT_NOTIFY"
+			| .linux.seccomp.syscalls += [{"names":["write"], "action":"SCMP_ACT_ALLOW"}]'
+
+	runc run test_busybox
+	[ "$status" -ne 0 ]
+	[[ "$output" == *"SCMP_ACT_NOTIFY cannot be used as default action"* ]]
+}
+
 @test "runc run [seccomp] (SCMP_ACT_NOTIFY wrong listener path)" {
 	scmp_act_notify_template "/bin/true" false '"mkdir"'
 	update_config '.linux.seccomp.listenerPath = "/some-non-existing-listener-path.sock"'
@@ -214,3 +224,32 @@ function scmp_act_notify_template() {
 	[ "$status" -eq 0 ]
 	[[ "$output" == *"chmod:"*"test-file"*"No medium found"* ]]
 }
+
+@test "runc run --seccomp-record" {
+	update_config '.process.args = ["/bin/sh", "-c", "echo hello; ls /"]'
+
+	runc run --seccomp-record "$BATS_TMPDIR/recorded.json" test_busybox
+	[ "$status" -eq 0 ]
+	[[ "$output" == *"hello"* ]]
+
+	profile=$(<"$BATS_TMPDIR/recorded.json")
+	rm -f "$BATS_TMPDIR/recorded.json"
+	[ "$(jq -r .defaultAction <<<"$profile")" = "SCMP_ACT_ERRNO" ]
+	jq -e '.syscalls[0].names | index("execve") and index("getdents64")' <<<"$profile"
+
+	# The recorded profile allows the recorded workload only.
+	update_config '.linux.seccomp = '"$profile"
+	runc run test_busybox
+	[ "$status" -eq 0 ]
+
+	update_config '.process.args = ["/bin/sh", "-c", "mkdir /dev/shm/foo"]'
+	runc run test_busybox
+	[ "$status" -ne 0 ]
+	[[ "$output" == *"mkdir:"*"/dev/shm/foo"*"Operation not permitted"* ]]
+}
+
+@test "runc run --seccomp-record --detach" {
+	runc run -d --seccomp-record "$BATS_TMPDIR/recorded.json" test_busybox
+	[ "$status" -ne 0 ]
+	[[ "$output" == *"can't be used with --detach"* ]]
+}
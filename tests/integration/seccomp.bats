@@ -66,6 +66,25 @@ function teardown() {
 	[[ "$output" == *"Network is down"* ]]
 }
 
+@test "runc run [seccomp] (landlock_restrict_self blocked)" {
+	if [ "$(__runc features | jq .linux.landlock.abi)" -lt 1 ]; then
+		skip "requires Landlock"
+	fi
+	# The Landlock ruleset is enforced after the seccomp filter is loaded,
+	# so landlock_restrict_self must be allowed despite the profile.
+	update_config '  .process.args = ["/bin/sh", "-c", "echo > /dev/null && echo > /dev/shm/foo"]
+			| .annotations["org.opencontainers.runc.landlock"] = "{\"handledAccessFS\": [\"write_file\"], \"rules\": [{\"paths\": [\"/dev/null\"], \"allowedAccess\": [\"write_file\"]}], \"abi\": 1}"
+			| .linux.seccomp = {
+				"defaultAction":"SCMP_ACT_ALLOW",
+				"syscalls":[{"names":["landlock_restrict_self"], "action":"SCMP_ACT_ERRNO"}]
+			}'
+
+	runc run test_busybox
+	[ "$status" -ne 0 ]
+	[[ "$output" == *"allowing landlock_restrict_self(2) despite the profile"* ]]
+	[[ "$output" == *"/dev/shm/foo"*"Permission denied"* ]]
+}
+
 @test "runc run [seccomp] (SCMP_ACT_KILL)" {
 	update_config '  .process.args = ["/bin/sh", "-c", "mkdir /dev/shm/foo"]
 			| .process.noNewPrivileges = false
@@ -99,3 +118,33 @@ function teardown() {
 	[[ "$output" == *"error running hook"* ]]
 	[[ "$output" == *"bad system call"* ]]
 }
+
+@test "runc debug seccomp" {
+	# The system calls are evaluated for the native architecture.
+	requires arch_x86_64
+
+	update_config '   .linux.seccomp = {
+				"defaultAction":"SCMP_ACT_ALLOW",
+				"architectures":["SCMP_ARCH_X86","SCMP_ARCH_X32"],
+				"syscalls":[{"names":["mkdir"], "action":"SCMP_ACT_ERRNO"}]
+			}'
+
+	runc debug seccomp --eval 'mkdir(0, 0755)' --eval getpid
+	[ "$status" -eq 0 ]
+	[[ "${lines[0]}" == "mkdir(0x0, 0x1ed) = SCMP_ACT_ERRNO(EPERM)" ]]
+	[[ "${lines[1]}" == "getpid() = SCMP_ACT_ALLOW" ]]
+
+	runc debug seccomp --dump
+	[ "$status" -eq 0 ]
+	[[ "$output" == *"A = nr"* ]]
+	[[ "$output" == *"mkdir"* ]]
+
+	# The profile alone can also be used.
+	jq .linux.seccomp config.json >profile.json
+	runc debug seccomp --config profile.json --eval 'mkdir'
+	[ "$status" -eq 0 ]
+	[[ "$output" == "mkdir() = SCMP_ACT_ERRNO(EPERM)" ]]
+
+	runc debug seccomp --eval 'mkdir(1,2,3,4,5,6,7)'
+	[ "$status" -ne 0 ]
+}
@@ -0,0 +1,44 @@
+#!/usr/bin/env bats
+
+load helpers
+
+function setup() {
+	setup_busybox
+}
+
+function teardown() {
+	teardown_bundle
+}
+
+@test "stats --no-stream" {
+	# XXX: currently cgroups require root containers.
+	requires root
+	init_cgroup_paths
+
+	runc run -d --console-socket "$CONSOLE_SOCKET" test_box1
+	[ "$status" -eq 0 ]
+	runc create --console-socket "$CONSOLE_SOCKET" test_box2
+	[ "$status" -eq 0 ]
+
+	runc stats --no-stream --interval 100ms
+	[ "$status" -eq 0 ]
+	[[ ${lines[0]} =~ ID\ +STATUS\ +CPU\ %\ +MEM\ USAGE\ /\ LIMIT ]]
+	[[ "${lines[1]}" == "test_box1"*"running"*"%"* ]]
+	[ "${#lines[@]}" -eq 2 ]
+
+	runc stats --no-stream --all --interval 100ms
+	[ "$status" -eq 0 ]
+	[[ "$output" == *"test_box2"*"created"* ]]
+
+	runc stats --no-stream --format json --interval 100ms test_box1
+	[ "$status" -eq 0 ]
+	[ "$(echo "$output" | jq -r '.[0].id')" = "test_box1" ]
+	[ "$(echo "$output" | jq '.[0].pids')" -ge 1 ]
+	[ "$(echo "$output" | jq '.[0].memoryLimit')" -gt 0 ]
+}
+
+@test "stats with a non-existent container" {
+	runc stats --no-stream test_none
+	[ "$status" -ne 0 ]
+	[[ "$output" == *"container does not exist"* ]]
+}
@@ -0,0 +1,37 @@
+#!/usr/bin/env bats
+
+load helpers
+
+function setup() {
+	requires timens
+	setup_busybox
+
+	update_config '.linux.namespaces += [{"type": "time"}]
+		| .linux.timeOffsets = {
+			"monotonic": {"secs": 1337, "nanosecs": 3141519},
+			"boottime": {"secs": -7, "nanosecs": 0}
+		}'
+}
+
+function teardown() {
+	teardown_bundle
+}
+
+@test "runc run [timens offsets]" {
+	update_config '.process.args = ["cat", "/proc/self/timens_offsets"]'
+
+	runc run test_busybox
+	[ "$status" -eq 0 ]
+	grep -E '^monotonic +1337 +3141519$' <<<"$output"
+	grep -E '^boottime +-7 +0$' <<<"$output"
+}
+
+@test "runc exec [timens offsets]" {
+	runc run -d --console-socket "$CONSOLE_SOCKET" test_busybox
+	[ "$status" -eq 0 ]
+
+	runc exec test_busybox cat /proc/self/timens_offsets
+	[ "$status" -eq 0 ]
+	grep -E '^monotonic +1337 +3141519$' <<<"$output"
+	grep -E '^boottime +-7 +0$' <<<"$output"
+}
@@ -0,0 +1,72 @@
+#!/usr/bin/env bats
+
+load helpers
+
+function setup() {
+	setup_busybox
+}
+
+function teardown() {
+	teardown_bundle
+}
+
+@test "runc wait (timeout)" {
+	runc run -d --console-socket "$CONSOLE_SOCKET" test_busybox
+	[ "$status" -eq 0 ]
+
+	runc wait --timeout 1s test_busybox
+	[ "$status" -ne 0 ]
+	[[ "$output" == *"timed out"* ]]
+
+	testcontainer test_busybox running
+}
+
+@test "runc wait (exit code)" {
+	# The detached container's init is reaped by someone else, so its exit
+	# status is obtained from its pidfd (PIDFD_GET_INFO).
+	requires_kernel 6.15
+
+	update_config '.process.args = ["sh", "-c", "sleep 1; exit 3"]'
+	runc run -d --console-socket "$CONSOLE_SOCKET" test_busybox
+	[ "$status" -eq 0 ]
+
+	runc wait --timeout 10s test_busybox
+	[ "$status" -eq 0 ]
+	[ "$(jq -r .id <<<"$output")" = "test_busybox" ]
+	[ "$(jq -r .status <<<"$output")" = "exited" ]
+	[ "$(jq .exitCode <<<"$output")" -eq 3 ]
+	[ "$(jq -r .signal <<<"$output")" = "null" ]
+
+	testcontainer test_busybox stopped
+}
+
+@test "runc wait (killed)" {
+	requires_kernel 6.15
+
+	runc run -d --console-socket "$CONSOLE_SOCKET" test_busybox
+	[ "$status" -eq 0 ]
+
+	(sleep 1 && __runc kill test_busybox KILL) &
+
+	runc wait --timeout 10s test_busybox
+	[ "$status" -eq 0 ]
+	[ "$(jq -r .id <<<"$output")" = "test_busybox" ]
+	[ "$(jq -r .status <<<"$output")" = "signaled" ]
+	[ "$(jq .exitCode <<<"$output")" -eq 137 ]
+	[ "$(jq -r .signal <<<"$output")" = "SIGKILL" ]
+
+	testcontainer test_busybox stopped
+}
+
+@test "runc wait (stopped)" {
+	update_config '.process.args = ["true"]'
+	runc run -d --console-socket "$CONSOLE_SOCKET" test_busybox
+	[ "$status" -eq 0 ]
+
+	wait_for_container 10 1 test_busybox stopped
+
+	# The exit status is known only if init is not reaped yet.
+	runc wait test_busybox
+	[ "$status" -eq 0 ]
+	[[ "$(jq -r .status <<<"$output")" =~ ^(exited|unknown)$ ]]
+}
@@ -9,6 +9,14 @@ type Event struct {
 	Data interface{} `json:"data,omitempty"`
 }
 
+// Pressure is the data of a "pressure" event.
+type Pressure struct {
+	// Resource is the resource under pressure, e.g. "memory".
+	Resource string `json:"resource"`
+	// Trigger is the pressure level or PSI trigger that fired.
+	Trigger string `json:"trigger"`
+}
+
 // stats is the runc specific stats structure for stability when encoding and decoding stats.
 type Stats struct {
 	CPU               Cpu                 `json:"cpu"`
@@ -43,11 +51,14 @@ type Blkio struct {
 	IoMergedRecursive       []BlkioEntry `json:"ioMergedRecursive,omitempty"`
 	IoTimeRecursive         []BlkioEntry `json:"ioTimeRecursive,omitempty"`
 	SectorsRecursive        []BlkioEntry `json:"sectorsRecursive,omitempty"`
+	PSI                     *PSIStats    `json:"psi,omitempty"`
 }
 
 type Pids struct {
-	Current uint64 `json:"current,omitempty"`
-	Limit   uint64 `json:"limit,omitempty"`
+	Current   uint64 `json:"current,omitempty"`
+	Limit     uint64 `json:"limit,omitempty"`
+	Peak      uint64 `json:"peak,omitempty"`
+	MaxEvents uint64 `json:"maxEvents,omitempty"`
 }
 
 type Throttling struct {
@@ -66,9 +77,22 @@ type CpuUsage struct {
 	User         uint64   `json:"user"`
 }
 
+type PSIData struct {
+	Avg10  float64 `json:"avg10"`
+	Avg60  float64 `json:"avg60"`
+	Avg300 float64 `json:"avg300"`
+	Total  uint64  `json:"total"`
+}
+
+type PSIStats struct {
+	Some PSIData `json:"some,omitempty"`
+	Full PSIData `json:"full,omitempty"`
+}
+
 type Cpu struct {
 	Usage      CpuUsage   `json:"usage,omitempty"`
 	Throttling Throttling `json:"throttling,omitempty"`
+	PSI        *PSIStats  `json:"psi,omitempty"`
 }
 
 type CPUSet struct {
@@ -99,6 +123,17 @@ type Memory struct {
 	Kernel    MemoryEntry       `json:"kernel,omitempty"`
 	KernelTCP MemoryEntry       `json:"kernelTCP,omitempty"`
 	Raw       map[string]uint64 `json:"raw,omitempty"`
+	Events    MemoryEvents      `json:"events,omitempty"`
+	PSI       *PSIStats         `json:"psi,omitempty"`
+}
+
+type MemoryEvents struct {
+	Low          uint64 `json:"low,omitempty"`
+	High         uint64 `json:"high,omitempty"`
+	Max          uint64 `json:"max,omitempty"`
+	OOM          uint64 `json:"oom,omitempty"`
+	OOMKill      uint64 `json:"oomKill,omitempty"`
+	OOMGroupKill uint64 `json:"oomGroupKill,omitempty"`
 }
 
 type L3CacheInfo struct {
@@ -141,9 +176,15 @@ type IntelRdt struct {
 }
 
 type NetworkInterface struct {
-	// Name is the name of the network interface.
+	// Name is the name of the network interface in the container network
+	// namespace.
 	Name string
 
+	// HostName is the name of the host side of a veth interface set up by
+	// runc. If the container network namespace can't be entered, the
+	// statistics of such interfaces are read from their host side.
+	HostName string `json:",omitempty"`
+
 	RxBytes   uint64
 	RxPackets uint64
 	RxErrors  uint64
@@ -152,4 +193,27 @@ type NetworkInterface struct {
 	TxPackets uint64
 	TxErrors  uint64
 	TxDropped uint64
+
+	Multicast  uint64
+	Collisions uint64
+
+	RxLengthErrors    uint64
+	RxOverErrors      uint64
+	RxCrcErrors       uint64
+	RxFrameErrors     uint64
+	RxFifoErrors      uint64
+	RxMissedErrors    uint64
+	TxAbortedErrors   uint64
+	TxCarrierErrors   uint64
+	TxFifoErrors      uint64
+	TxHeartbeatErrors uint64
+	TxWindowErrors    uint64
+	RxCompressed      uint64
+	TxCompressed      uint64
+
+	// TxQdiscDropped and RxQdiscDropped are the numbers of packets dropped
+	// by the egress and ingress bandwidth limits, respectively. They are
+	// only reported when the container network namespace can be entered.
+	TxQdiscDropped uint64 `json:",omitempty"`
+	RxQdiscDropped uint64 `json:",omitempty"`
 }
@@ -41,6 +41,24 @@ type Linux struct {
 	Seccomp  *Seccomp  `json:"seccomp,omitempty"`
 	Apparmor *Apparmor `json:"apparmor,omitempty"`
 	Selinux  *Selinux  `json:"selinux,omitempty"`
+	Landlock *Landlock `json:"landlock,omitempty"`
+
+	MountExtensions *MountExtensions `json:"mountExtensions,omitempty"`
+}
+
+// MountExtensions represents the "mountExtensions" field.
+type MountExtensions struct {
+	// IDMap represents the status of idmap mounts support.
+	IDMap *IDMap `json:"idmap,omitempty"`
+}
+
+// IDMap represents the "idmap" field.
+type IDMap struct {
+	// Enabled represents whether idmap mounts support is compiled in.
+	// Unrelated to whether the host supports it or not.
+	// Nil value means "unknown", not "false".
+	// Always true in the current version of runc.
+	Enabled *bool `json:"enabled,omitempty"`
 }
 
 // Seccomp represents the "seccomp" field.
@@ -80,6 +98,23 @@ type Selinux struct {
 	Enabled *bool `json:"enabled,omitempty"`
 }
 
+// Landlock represents the "landlock" field.
+type Landlock struct {
+	// Enabled is true if Landlock support is compiled in.
+	// Unrelated to whether the host supports Landlock or not.
+	// Nil value means "unknown", not "false".
+	// Always true in the current version of runc.
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// ABI is the Landlock ABI version supported by the host kernel.
+	// Zero value means Landlock is not supported or disabled on the host.
+	ABI int `json:"abi"`
+
+	// AccessFS is the list of the recognized filesystem access rights, e.g., "read_file".
+	// Nil value means "unknown", not "no support for any access right".
+	AccessFS []string `json:"accessFS,omitempty"`
+}
+
 // Cgroup represents the "cgroup" field.
 type Cgroup struct {
 	// V1 represents whether Cgroup v1 support is compiled in.
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
 	"os"
 	"strconv"
 
@@ -12,6 +13,7 @@ import (
 
 	"github.com/docker/go-units"
 	"github.com/opencontainers/runc/libcontainer/configs"
+	"github.com/opencontainers/runc/libcontainer/configs/validate"
 	"github.com/opencontainers/runc/libcontainer/intelrdt"
 	"github.com/opencontainers/runtime-spec/specs-go"
 	"github.com/urfave/cli"
@@ -112,6 +114,26 @@ other options are ignored.
 			Name:  "memory-swap",
 			Usage: "Total memory usage (memory + swap); set '-1' to enable unlimited swap",
 		},
+		cli.StringFlag{
+			Name:  "memory-high",
+			Usage: "Memory usage throttle limit (in bytes); set '-1' to remove the limit (cgroup v2 only)",
+		},
+		cli.StringFlag{
+			Name:  "memory-min",
+			Usage: "Memory protected from reclaim (in bytes) (cgroup v2 only)",
+		},
+		cli.StringFlag{
+			Name:  "memory-swap-high",
+			Usage: "Swap usage throttle limit (in bytes); set '-1' to remove the limit (cgroup v2 only)",
+		},
+		cli.StringFlag{
+			Name:  "memory-zswap-max",
+			Usage: "Zswap usage limit (in bytes); set '-1' to remove the limit (cgroup v2 only)",
+		},
+		cli.StringFlag{
+			Name:  "memory-oom-group",
+			Usage: "Whether the OOM killer should kill all the container processes together (true or false) (cgroup v2 only)",
+		},
 		cli.IntFlag{
 			Name:  "pids-limit",
 			Usage: "Maximum number of pids allowed in the container",
@@ -124,6 +146,22 @@ other options are ignored.
 			Name:  "mem-bw-schema",
 			Usage: "The string of Intel RDT/MBA memory bandwidth schema",
 		},
+		cli.StringFlag{
+			Name:  "net-egress-rate",
+			Usage: "Bandwidth limit of the traffic sent by the container (in bytes per second); set '0' to remove the limit",
+		},
+		cli.StringFlag{
+			Name:  "net-egress-burst",
+			Usage: "Amount of data the container can send at once above the egress rate (in bytes)",
+		},
+		cli.StringFlag{
+			Name:  "net-ingress-rate",
+			Usage: "Bandwidth limit of the traffic received by the container (in bytes per second); set '0' to remove the limit",
+		},
+		cli.StringFlag{
+			Name:  "net-ingress-burst",
+			Usage: "Amount of data the container can receive at once above the ingress rate (in bytes)",
+		},
 	},
 	Action: func(context *cli.Context) error {
 		if err := checkArgs(context, 1, exactArgs); err != nil {
@@ -298,6 +336,42 @@ other options are ignored.
 		config.Cgroups.Resources.PidsLimit = r.Pids.Limit
 		config.Cgroups.Resources.Unified = r.Unified
 
+		// The cgroup v2 only memory settings are not a part of the
+		// runtime spec, so they can only be changed with options.
+		// Those not specified are left at their old values.
+		if context.String("resources") == "" {
+			for _, pair := range []struct {
+				opt  string
+				dest **int64
+			}{
+				{"memory-high", &config.Cgroups.Resources.MemoryHigh},
+				{"memory-min", &config.Cgroups.Resources.MemoryMin},
+				{"memory-swap-high", &config.Cgroups.Resources.MemorySwapHigh},
+				{"memory-zswap-max", &config.Cgroups.Resources.MemoryZswapMax},
+			} {
+				if val := context.String(pair.opt); val != "" {
+					v := int64(-1)
+					if val != "-1" {
+						v, err = units.RAMInBytes(val)
+						if err != nil {
+							return fmt.Errorf("invalid value for %s: %w", pair.opt, err)
+						}
+					}
+					*pair.dest = &v
+				}
+			}
+			if val := context.String("memory-oom-group"); val != "" {
+				group, err := strconv.ParseBool(val)
+				if err != nil {
+					return fmt.Errorf("invalid value for memory-oom-group: %w", err)
+				}
+				config.Cgroups.Resources.MemoryOOMGroup = &group
+			}
+			if err := updateNetworkBandwidth(context, &config); err != nil {
+				return err
+			}
+		}
+
 		// Update Intel RDT
 		l3CacheSchema := context.String("l3-cache-schema")
 		memBwSchema := context.String("mem-bw-schema")
@@ -339,3 +413,81 @@ other options are ignored.
 		return container.Set(config)
 	},
 }
+
+// updateNetworkBandwidth sets the bandwidth limits of all the container
+// networks but loopback from the --net-* options. Those not specified are
+// left at their old values.
+func updateNetworkBandwidth(context *cli.Context, config *configs.Config) error {
+	type limit struct {
+		rate, burst string
+		get         func(*configs.Network) **configs.Bandwidth
+	}
+	limits := []limit{
+		{"net-egress-rate", "net-egress-burst", func(n *configs.Network) **configs.Bandwidth { return &n.Egress }},
+		{"net-ingress-rate", "net-ingress-burst", func(n *configs.Network) **configs.Bandwidth { return &n.Ingress }},
+	}
+	var set bool
+	for _, l := range limits {
+		if context.String(l.rate) != "" || context.String(l.burst) != "" {
+			set = true
+		}
+	}
+	if !set {
+		return nil
+	}
+
+	// The networks (and the slice holding them) are shared with the current
+	// config, which is compared with the new one to apply the changes, so
+	// copy them before making changes.
+	config.Networks = append([]*configs.Network(nil), config.Networks...)
+	var found bool
+	for i, n := range config.Networks {
+		if n.Type == "loopback" {
+			continue
+		}
+		found = true
+		nc := *n
+		for _, l := range limits {
+			b := l.get(&nc)
+			if val := context.String(l.rate); val != "" {
+				rate, err := units.RAMInBytes(val)
+				if err != nil || rate < 0 {
+					return fmt.Errorf("invalid value for %s: %q", l.rate, val)
+				}
+				if rate == 0 {
+					*b = nil
+				} else {
+					bc := configs.Bandwidth{}
+					if *b != nil {
+						bc = **b
+					}
+					bc.Rate = uint64(rate)
+					*b = &bc
+				}
+			}
+			if val := context.String(l.burst); val != "" {
+				if *b == nil {
+					return fmt.Errorf("%s requires a rate limit", l.burst)
+				}
+				burst, err := units.RAMInBytes(val)
+				if err != nil || burst < 0 || burst > math.MaxUint32 {
+					return fmt.Errorf("invalid value for %s: %q", l.burst, val)
+				}
+				bc := **b
+				bc.Burst = uint32(burst)
+				*b = &bc
+			}
+		}
+		if nc.Egress == nil {
+			nc.Qdisc = ""
+		}
+		if err := validate.Bandwidth(&nc); err != nil {
+			return err
+		}
+		config.Networks[i] = &nc
+	}
+	if !found {
+		return errors.New("the container has no network to limit the bandwidth of")
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"flag"
+	"testing"
+
+	"github.com/opencontainers/runc/libcontainer/configs"
+	"github.com/urfave/cli"
+)
+
+func TestUpdateNetworkBandwidth(t *testing.T) {
+	set := flag.NewFlagSet("update", flag.ContinueOnError)
+	for _, f := range updateCommand.Flags {
+		f.Apply(set)
+	}
+	if err := set.Parse([]string{"--net-egress-rate", "2M", "--net-ingress-rate", "1M"}); err != nil {
+		t.Fatal(err)
+	}
+	context := cli.NewContext(nil, set, nil)
+
+	veth := &configs.Network{
+		Type:   "veth",
+		Name:   "eth0",
+		Egress: &configs.Bandwidth{Rate: 1 << 20, Burst: 1 << 16},
+	}
+	loopback := &configs.Network{Type: "loopback"}
+	current := configs.Config{Networks: []*configs.Network{loopback, veth}}
+
+	// Like the config returned by container.Config, the new config shares
+	// its networks with the current one.
+	config := current
+	if err := updateNetworkBandwidth(context, &config); err != nil {
+		t.Fatal(err)
+	}
+
+	n := config.Networks[1]
+	if n.Egress == nil || n.Egress.Rate != 2<<20 || n.Egress.Burst != 1<<16 {
+		t.Errorf("expected egress rate %d and burst %d, got %+v", 2<<20, 1<<16, n.Egress)
+	}
+	if n.Ingress == nil || n.Ingress.Rate != 1<<20 {
+		t.Errorf("expected ingress rate %d, got %+v", 1<<20, n.Ingress)
+	}
+	if config.Networks[0] != loopback {
+		t.Error("expected the loopback network to be left as is")
+	}
+
+	// The current config must be left unchanged, so that container.Set
+	// sees the new limits.
+	if current.Networks[0] != loopback || current.Networks[1] != veth {
+		t.Fatal("the networks of the current config were replaced")
+	}
+	if veth.Egress.Rate != 1<<20 || veth.Egress.Burst != 1<<16 || veth.Ingress != nil {
+		t.Errorf("the current network was modified: egress %+v, ingress %+v", veth.Egress, veth.Ingress)
+	}
+}
@@ -17,6 +17,7 @@ import (
 
 	"github.com/opencontainers/runc/libcontainer"
 	"github.com/opencontainers/runc/libcontainer/configs"
+	"github.com/opencontainers/runc/libcontainer/configs/validate"
 	"github.com/opencontainers/runc/libcontainer/specconv"
 	"github.com/opencontainers/runc/libcontainer/utils"
 )
@@ -68,6 +69,8 @@ func newProcess(p specs.Process) (*libcontainer.Process, error) {
 		Label:           p.SelinuxLabel,
 		NoNewPrivileges: &p.NoNewPrivileges,
 		AppArmorProfile: p.ApparmorProfile,
+		Scheduler:       configs.NewScheduler(p.Scheduler),
+		IOPriority:      p.IOPriority,
 	}
 
 	if p.ConsoleSize != nil {
@@ -176,7 +179,7 @@ func createPidFile(path string, process *libcontainer.Process) error {
 	return os.Rename(tmpName, path)
 }
 
-func createContainer(context *cli.Context, id string, spec *specs.Spec) (libcontainer.Container, error) {
+func createContainer(context *cli.Context, id string, spec *specs.Spec, seccompRecorder *seccompRecorder) (libcontainer.Container, error) {
 	rootlessCg, err := shouldUseRootlessCgroupManager(context)
 	if err != nil {
 		return nil, err
@@ -193,6 +196,9 @@ func createContainer(context *cli.Context, id string, spec *specs.Spec) (libcont
 	if err != nil {
 		return nil, err
 	}
+	if seccompRecorder != nil {
+		seccompRecorder.setupConfig(config)
+	}
 
 	factory, err := loadFactory(context)
 	if err != nil {
@@ -234,6 +240,10 @@ func (r *runner) run(config *specs.Process) (int, error) {
 	process.LogLevel = strconv.Itoa(int(logrus.GetLevel()))
 	// Populate the fields that come from runner.
 	process.Init = r.init
+	if !r.init {
+		// The spec's execCPUAffinity does not apply to the container init.
+		process.CPUAffinity = config.ExecCPUAffinity
+	}
 	process.SubCgroupPaths = r.subCgroupPaths
 	if len(r.listenFDs) > 0 {
 		process.Env = append(process.Env, "LISTEN_FDS="+strconv.Itoa(len(r.listenFDs)), "LISTEN_PID=1")
@@ -342,6 +352,21 @@ func validateProcessSpec(spec *specs.Process) error {
 	if spec.SelinuxLabel != "" && !selinux.GetEnabled() {
 		return errors.New("selinux label is specified in config, but selinux is disabled or not supported")
 	}
+	if spec.Scheduler != nil {
+		if err := validate.Scheduler(configs.NewScheduler(spec.Scheduler)); err != nil {
+			return err
+		}
+	}
+	if spec.IOPriority != nil {
+		if err := validate.IOPriority(spec.IOPriority); err != nil {
+			return err
+		}
+	}
+	if spec.ExecCPUAffinity != nil {
+		if err := validate.CPUAffinity(spec.ExecCPUAffinity); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -372,7 +397,11 @@ func startContainer(context *cli.Context, action CtAct, criuOpts *libcontainer.C
 		notifySocket.setupSpec(spec)
 	}
 
-	container, err := createContainer(context, id, spec)
+	seccompRecorder, err := newSeccompRecorder(context, id)
+	if err != nil {
+		return -1, err
+	}
+	container, err := createContainer(context, id, spec, seccompRecorder)
 	if err != nil {
 		return -1, err
 	}
@@ -388,6 +417,13 @@ func startContainer(context *cli.Context, action CtAct, criuOpts *libcontainer.C
 		}
 	}
 
+	if seccompRecorder != nil {
+		if err := seccompRecorder.start(); err != nil {
+			return -1, err
+		}
+		defer seccompRecorder.Close()
+	}
+
 	// Support on-demand socket activation by passing file descriptors into the container init process.
 	listenFDs := []*os.File{}
 	if os.Getenv("LISTEN_FDS") != "" {
@@ -408,5 +444,9 @@ func startContainer(context *cli.Context, action CtAct, criuOpts *libcontainer.C
 		criuOpts:        criuOpts,
 		init:            true,
 	}
-	return r.run(spec.Process)
+	status, err := r.run(spec.Process)
+	if err == nil && seccompRecorder != nil {
+		err = seccompRecorder.writeProfile()
+	}
+	return status, err
 }
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/urfave/cli"
+	"golang.org/x/sys/unix"
+)
+
+// exitStatus is the JSON output of runc wait.
+type exitStatus struct {
+	ID string `json:"id"`
+	// Status is "exited", "signaled", or "unknown" if the exit status of
+	// the container's init process can not be determined.
+	Status string `json:"status"`
+	// ExitCode is the exit code of the container's init process, or 128
+	// plus the signal number if it was killed by a signal. It is omitted
+	// if the exit status is unknown.
+	ExitCode *int `json:"exitCode,omitempty"`
+	// Signal is the name of the signal which killed init, if any.
+	Signal string `json:"signal,omitempty"`
+}
+
+var waitCommand = cli.Command{
+	Name:  "wait",
+	Usage: "wait for a container to stop and output its exit status",
+	ArgsUsage: `<container-id>
+
+Where "<container-id>" is your name for the instance of the container.`,
+	Description: `The wait command blocks until the container's init process exits, then
+outputs its exit status in a JSON format.
+
+The exit status can be obtained if the init process has not yet been reaped by
+its parent or, on Linux 6.15 and later, if it exits while runc wait is running.
+Otherwise, the status is reported as "unknown".`,
+	Flags: []cli.Flag{
+		cli.DurationFlag{
+			Name:  "timeout",
+			Usage: "maximum time to wait for the container to stop (0 means no limit)",
+		},
+	},
+	Action: func(context *cli.Context) error {
+		if err := checkArgs(context, 1, exactArgs); err != nil {
+			return err
+		}
+		container, err := getContainer(context)
+		if err != nil {
+			return err
+		}
+		st, err := container.Wait(context.Duration("timeout"))
+		if err != nil {
+			return err
+		}
+		es := exitStatus{ID: container.ID(), Status: "unknown"}
+		if st != nil {
+			es.Status = "exited"
+			es.ExitCode = &st.Code
+			if st.Signal != 0 {
+				es.Status = "signaled"
+				es.Signal = unix.SignalName(st.Signal)
+			}
+		}
+		data, err := json.MarshalIndent(es, "", "  ")
+		if err != nil {
+			return err
+		}
+		os.Stdout.Write(data)
+		return nil
+	},
+}